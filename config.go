@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// config holds the plugin parameters passed through the protoc/buf "opt"
+type config struct {
+	// DiscardUnknown makes the generated handlers ignore unknown tool
+	// arguments instead of rejecting them
+	DiscardUnknown bool
+	// Output selects how responses are returned: "text" for one
+	// "Field: value" text content per field, "json" for the protojson
+	// encoded response, or "structured" for the JSON response as
+	// structuredContent with a generated outputSchema
+	Output string
+	// EnumNumbers allows enum numbers alongside the value names in the
+	// generated schemas
+	EnumNumbers bool
+	// Validate makes the generated handlers check the request against its
+	// protovalidate rules before calling the service
+	Validate bool
+	// ToolNaming selects how tool names are derived from methods: "method"
+	// for the method name, "snake" for snake_case, "camel" for lowerCamelCase,
+	// "service" for Service.Method, or "qualified" for the full proto name
+	// joined with underscores
+	ToolNaming string
+	// FieldNaming selects how parameters are named after fields: "json" for
+	// the proto JSON name, "proto" for the field name as declared, or "go"
+	// for the Go field name
+	FieldNaming string
+	// ServeFuncs enables the package-level McpServices, NewMcpServer, and
+	// ServeStdio declarations generated once per Go package
+	ServeFuncs bool
+	// InterfaceSuffix is appended to service names to name the generated
+	// interfaces and registration functions
+	InterfaceSuffix string
+	// ServiceFilter lists the full names of the services to generate tools
+	// for; all services are generated when empty
+	ServiceFilter []string
+	// EmptyFiles makes the plugin emit a file for proto files without
+	// (selected) services instead of skipping them
+	EmptyFiles bool
+	// BidiSessions makes bidirectional streaming methods generate a set of
+	// session tools instead of failing the generation
+	BidiSessions bool
+	// BidiIdleTimeout is how long a bidirectional streaming session may go
+	// without a tool call before it is canceled
+	BidiIdleTimeout time.Duration
+}
+
+// identSuffix matches strings that can be appended to a Go identifier
+var identSuffix = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// flags returns a flag set parsing plugin parameters into cfg, after setting
+// cfg to the defaults
+func (cfg *config) flags() *flag.FlagSet {
+	flags := flag.NewFlagSet("protoc-gen-mcpserver", flag.ContinueOnError)
+	flags.BoolVar(&cfg.DiscardUnknown, "discard_unknown", false, "Ignore unknown tool arguments instead of rejecting them")
+	flags.Var(newChoice(&cfg.Output, "json", "text", "structured"), "output", "Response format")
+	flags.BoolVar(&cfg.EnumNumbers, "enum_numbers", false, "Allow enum numbers alongside value names in schemas")
+	flags.BoolVar(&cfg.Validate, "validate", false, "Validate requests with protovalidate before calling the service")
+	flags.Var(newChoice(&cfg.ToolNaming, "method", "snake", "camel", "service", "qualified"), "tool_naming", "Tool naming strategy")
+	flags.Var(newChoice(&cfg.FieldNaming, "json", "proto", "go"), "field_naming", "Parameter naming strategy")
+	flags.BoolVar(&cfg.ServeFuncs, "serve_funcs", true, "Generate the package-level NewMcpServer and ServeStdio functions")
+	cfg.InterfaceSuffix = "McpServer"
+	flags.Func("interface_suffix", "Suffix of the generated interface names", func(value string) error {
+		if !identSuffix.MatchString(value) {
+			return fmt.Errorf("must contain only letters, digits, and underscores")
+		}
+		cfg.InterfaceSuffix = value
+		return nil
+	})
+	flags.Func("services", "Full name of a service to generate; may be repeated", func(value string) error {
+		cfg.ServiceFilter = append(cfg.ServiceFilter, value)
+		return nil
+	})
+	flags.BoolVar(&cfg.EmptyFiles, "empty_files", false, "Generate files for proto files without services")
+	flags.BoolVar(&cfg.BidiSessions, "bidi_sessions", false, "Generate session tools for bidirectional streaming methods")
+	cfg.BidiIdleTimeout = 5 * time.Minute
+	flags.Func("bidi_idle_timeout", "Idle time after which bidirectional streaming sessions are canceled", func(value string) error {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("must be positive")
+		}
+		cfg.BidiIdleTimeout = timeout
+		return nil
+	})
+	return flags
+}
+
+// services returns the services of a file selected by the services parameter
+func (cfg *config) services(file *protogen.File) []*protogen.Service {
+	if len(cfg.ServiceFilter) == 0 {
+		return file.Services
+	}
+	var services []*protogen.Service
+	for _, service := range file.Services {
+		for _, name := range cfg.ServiceFilter {
+			if string(service.Desc.FullName()) == name {
+				services = append(services, service)
+				break
+			}
+		}
+	}
+	return services
+}
+
+// checkServiceFilter reports names given to the services parameter that
+// match no service of the files being generated
+func (cfg *config) checkServiceFilter(files []*protogen.File) error {
+	for _, name := range cfg.ServiceFilter {
+		found := false
+		for _, file := range files {
+			if file.Generate && file.Desc.Services().ByName(protoreflect.FullName(name).Name()) != nil &&
+				file.Desc.Package() == protoreflect.FullName(name).Parent() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid value %q for parameter services: no such service in the generated files", name)
+		}
+	}
+	return nil
+}
+
+// setParam sets a plugin parameter, naming the parameter in the error
+func setParam(flags *flag.FlagSet, name, value string) error {
+	if flags.Lookup(name) == nil {
+		return fmt.Errorf("unknown parameter %q", name)
+	}
+	if err := flags.Set(name, value); err != nil {
+		return fmt.Errorf("invalid value %q for parameter %s: %v", value, name, err)
+	}
+	return nil
+}
+
+// choice is a flag.Value restricted to a fixed set of strings
+type choice struct {
+	value   *string
+	choices []string
+}
+
+// newChoice returns a choice storing into value, which is set to the first
+// of choices as the default
+func newChoice(value *string, choices ...string) *choice {
+	*value = choices[0]
+	return &choice{value: value, choices: choices}
+}
+
+func (c *choice) String() string {
+	if c.value == nil {
+		return ""
+	}
+	return *c.value
+}
+
+func (c *choice) Set(value string) error {
+	for _, choice := range c.choices {
+		if value == choice {
+			*c.value = value
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(c.choices, ", "))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	var cfg config
+	cfg.flags()
+	want := config{
+		Output:          "json",
+		ToolNaming:      "method",
+		FieldNaming:     "json",
+		ServeFuncs:      true,
+		InterfaceSuffix: "McpServer",
+		BidiIdleTimeout: 5 * time.Minute,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("defaults = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestSetParam(t *testing.T) {
+	var cfg config
+	flags := cfg.flags()
+	for name, value := range map[string]string{
+		"discard_unknown":   "true",
+		"output":            "structured",
+		"enum_numbers":      "true",
+		"validate":          "true",
+		"tool_naming":       "qualified",
+		"field_naming":      "proto",
+		"serve_funcs":       "false",
+		"interface_suffix":  "",
+		"empty_files":       "true",
+		"bidi_sessions":     "true",
+		"bidi_idle_timeout": "90s",
+	} {
+		if err := setParam(flags, name, value); err != nil {
+			t.Errorf("setParam(%s, %q): %v", name, value, err)
+		}
+	}
+	for _, name := range []string{"a.B", "c.D"} {
+		if err := setParam(flags, "services", name); err != nil {
+			t.Errorf("setParam(services, %q): %v", name, err)
+		}
+	}
+	want := config{
+		DiscardUnknown:  true,
+		Output:          "structured",
+		EnumNumbers:     true,
+		Validate:        true,
+		ToolNaming:      "qualified",
+		FieldNaming:     "proto",
+		ServiceFilter:   []string{"a.B", "c.D"},
+		EmptyFiles:      true,
+		BidiSessions:    true,
+		BidiIdleTimeout: 90 * time.Second,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("config = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestSetParamErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, value string
+		want        string
+	}{
+		{"outputs", "json", `unknown parameter "outputs"`},
+		{"output", "yaml", `invalid value "yaml" for parameter output: must be one of json, text, structured`},
+		{"tool_naming", "kebab", `invalid value "kebab" for parameter tool_naming: must be one of method, snake, camel, service, qualified`},
+		{"field_naming", "camel", `invalid value "camel" for parameter field_naming: must be one of json, proto, go`},
+		{"enum_numbers", "maybe", `invalid value "maybe" for parameter enum_numbers`},
+		{"interface_suffix", "Mcp-Server", `invalid value "Mcp-Server" for parameter interface_suffix: must contain only letters, digits, and underscores`},
+		{"bidi_idle_timeout", "5", `invalid value "5" for parameter bidi_idle_timeout`},
+		{"bidi_idle_timeout", "0s", `invalid value "0s" for parameter bidi_idle_timeout: must be positive`},
+	} {
+		var cfg config
+		err := setParam(cfg.flags(), tt.name, tt.value)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("setParam(%s, %q) = %v, want %q", tt.name, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestServiceFilter(t *testing.T) {
+	gen, cfg, err := newPlugin(t, "services=golden.Otp", "golden/tree.proto", "golden/2fa.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.checkServiceFilter(gen.Files); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range gen.Files {
+		if !file.Generate {
+			continue
+		}
+		var names []string
+		for _, service := range cfg.services(file) {
+			names = append(names, string(service.Desc.Name()))
+		}
+		want := map[string][]string{"golden/2fa.proto": {"Otp"}}[file.Desc.Path()]
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("services of %s = %v, want %v", file.Desc.Path(), names, want)
+		}
+	}
+
+	// The filter names services by full name, so a service of another
+	// package isn't matched.
+	gen, cfg, err = newPlugin(t, "services=other.Otp", "golden/2fa.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.checkServiceFilter(gen.Files); err == nil || !strings.HasPrefix(err.Error(), `invalid value "other.Otp" for parameter services`) {
+		t.Errorf("checkServiceFilter = %v, want an error about a service of another package", err)
+	}
+}
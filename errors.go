@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// descriptorError returns an error about a proto element, prefixed with its
+// source position when known and its full name
+func descriptorError(desc protoreflect.Descriptor, format string, args ...interface{}) error {
+	file := desc.ParentFile()
+	pos := file.Path()
+	if loc := file.SourceLocations().ByDescriptor(desc); loc.Path != nil {
+		pos = fmt.Sprintf("%s:%d:%d", file.Path(), loc.StartLine+1, loc.StartColumn+1)
+	}
+	return fmt.Errorf("%s: %s: %s", pos, desc.FullName(), fmt.Sprintf(format, args...))
+}
+
+// checkSupported reports the constructs of services the generated code can't
+// handle, so that generation fails instead of producing code that doesn't
+// compile
+func checkSupported(cfg config, services []*protogen.Service) error {
+	for _, service := range services {
+		for _, method := range service.Methods {
+			for _, msg := range []*protogen.Message{method.Input, method.Output} {
+				for _, field := range msg.Fields {
+					if fieldOptions(field).GetMimeType() != "" && (field.Desc.Kind() != protoreflect.BytesKind || field.Desc.IsMap()) {
+						return descriptorError(field.Desc, "mime_type is only supported on bytes fields")
+					}
+				}
+			}
+			// Tool parameters are the fields of the request, but well-known
+			// types other than Empty have their own JSON form, such as the
+			// string of a Timestamp. Client-streaming items take that form.
+			if !isClientStreaming(method) && isWellKnown(method.Input) && method.Input.Desc.FullName() != "google.protobuf.Empty" {
+				return descriptorError(method.Desc, "%s requests are only supported by client-streaming RPCs; wrap it in a request message", method.Input.Desc.FullName())
+			}
+			if !isBidiStreaming(method) {
+				continue
+			}
+			if !cfg.BidiSessions {
+				return descriptorError(method.Desc, "bidirectional streaming RPCs are only supported with bidi_sessions=true")
+			}
+			for _, field := range method.Input.Fields {
+				for _, name := range append([]string{field.Desc.JSONName()}, argAliases(field)...) {
+					if name == "session" {
+						return descriptorError(field.Desc, "field collides with the session parameter of the %s_send tool", toolName(cfg, method))
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
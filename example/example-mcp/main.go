@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"io"
 	"log"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	. "github.com/wricardo/protoc-gen-mcpserver/example"
 )
 
@@ -26,21 +29,65 @@ func (s *GreetServer) CheckStatus(ctx context.Context, req *CheckStatusRequest)
 }
 
 func (s *GreetServer) ComplexOperation(ctx context.Context, req *ComplexOperationRequest) (*ComplexOperationResponse, error) {
+	tagCounts := make(map[string]int32)
+	for _, tag := range req.Tags {
+		tagCounts[tag]++
+	}
 	return &ComplexOperationResponse{
 		Success:     true,
 		OperationId: "12345",
 		StatusCode:  200,
 		Results:     []string{"Result1", "Result2"},
 		Average:     10.5,
+		TagCounts:   tagCounts,
+		CompletedAt: timestamppb.Now(),
 	}, nil
 }
 
 func (s *GreetServer) GreetPerson(ctx context.Context, req *GreetPersonRequest) (*GreetPersonResponse, error) {
+	name := req.FirstName + " " + req.LastName
+	if req.Nickname != nil {
+		name = req.GetNickname()
+	}
 	return &GreetPersonResponse{
-		Greeting: "Hello, " + req.FirstName + " " + req.LastName,
+		Greeting: "Hello, " + name,
+	}, nil
+}
+
+// RegisterContact implements example.ExampleServiceMcpServer.
+func (s *GreetServer) RegisterContact(ctx context.Context, req *RegisterContactRequest) (*RegisterContactResponse, error) {
+	return &RegisterContactResponse{
+		ContactId: "contact-1",
+		Contact:   req.Contact,
 	}, nil
 }
 
+// CountDown implements example.ExampleServiceMcpServer.
+func (s *GreetServer) CountDown(ctx context.Context, req *CountDownRequest, send func(*CountDownResponse) error) error {
+	for i := req.From; i >= 0; i-- {
+		if err := send(&CountDownResponse{Remaining: i}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SumAll implements example.ExampleServiceMcpServer.
+func (s *GreetServer) SumAll(ctx context.Context, stream ExampleServiceMcpServer_SumAllStream) (*CalculateSumResponse, error) {
+	res := &CalculateSumResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Sum += req.Number1 + req.Number2
+		res.Product += float64(req.Number1+req.Number2) * req.Factor
+	}
+}
+
 // ProcessNames implements example.ExampleServiceMcpServer.
 func (s *GreetServer) ProcessNames(ctx context.Context, req *ProcessNamesRequest) (*ProcessNamesResponse, error) {
 	return &ProcessNamesResponse{
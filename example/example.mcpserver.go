@@ -2,11 +2,18 @@
 package example
 
 import (
-	"context"
-	"fmt"
-
-	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
+	context "context"
+	json "encoding/json"
+	fmt "fmt"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	server "github.com/mark3labs/mcp-go/server"
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	status "google.golang.org/grpc/status"
+	protojson "google.golang.org/protobuf/encoding/protojson"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	io "io"
+	strconv "strconv"
+	strings "strings"
 )
 
 type ExampleServiceMcpServer interface {
@@ -15,258 +22,533 @@ type ExampleServiceMcpServer interface {
 	CheckStatus(ctx context.Context, req *CheckStatusRequest) (*CheckStatusResponse, error)
 	ProcessNames(ctx context.Context, req *ProcessNamesRequest) (*ProcessNamesResponse, error)
 	ComplexOperation(ctx context.Context, req *ComplexOperationRequest) (*ComplexOperationResponse, error)
+	RegisterContact(ctx context.Context, req *RegisterContactRequest) (*RegisterContactResponse, error)
+	CountDown(ctx context.Context, req *CountDownRequest, send func(*CountDownResponse) error) error
+	SumAll(ctx context.Context, stream ExampleServiceMcpServer_SumAllStream) (*CalculateSumResponse, error)
+}
+
+// ExampleServiceMcpServer_SumAllStream passes the items of the SumAll tool call to
+// ExampleServiceMcpServer.SumAll
+type ExampleServiceMcpServer_SumAllStream interface {
+	// Recv returns the next item, or io.EOF after the last one
+	Recv() (*CalculateSumRequest, error)
+}
+
+type exampleServiceMcpServer_SumAllStream struct {
+	items []*CalculateSumRequest
+}
+
+func (s *exampleServiceMcpServer_SumAllStream) Recv() (*CalculateSumRequest, error) {
+	if len(s.items) == 0 {
+		return nil, io.EOF
+	}
+	item := s.items[0]
+	s.items = s.items[1:]
+	return item, nil
 }
 
 func RegisterExampleServiceMcpServer(s *server.MCPServer, srv ExampleServiceMcpServer) {
 	s.AddTool(
 		mcp.NewTool(
 			"GreetPerson",
-			mcp.WithDescription("GreetPerson description"),
+			mcp.WithDescription("GreetPerson uses string parameters"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "GreetPerson",
 			}),
-			mcp.WithString("FirstName", mcp.Description("Parameter FirstName")),
-			mcp.WithString("LastName", mcp.Description("Parameter LastName")),
+			mcp.WithString("firstName", mcp.Description("Parameter firstName")),
+			mcp.WithString("lastName", mcp.Description("Parameter lastName")),
+			mcp.WithString("nickname", mcp.Description("Preferred name, used instead of the first name when set"), mcp.PropertyOption(func(schema map[string]interface{}) { schema["type"] = []interface{}{"string", "null"} })),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "first_name", "FirstName":
+					name = "firstName"
+				case "last_name", "LastName":
+					name = "lastName"
+				case "Nickname":
+					name = "nickname"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
 			req := &GreetPersonRequest{}
-			req.FirstName = mcp.ParseString(request, "FirstName", "")
-			req.LastName = mcp.ParseString(request, "LastName", "")
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
 			res, err := srv.GreetPerson(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Greeting: "+res.Greeting))
-
-			return result, nil
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 	s.AddTool(
 		mcp.NewTool(
 			"CalculateSum",
-			mcp.WithDescription("CalculateSum description"),
+			mcp.WithDescription("CalculateSum demonstrates number parameters"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "CalculateSum",
 			}),
-			mcp.WithNumber("Number1", mcp.Description("Parameter Number1")),
-			mcp.WithNumber("Number2", mcp.Description("Parameter Number2")),
-			mcp.WithNumber("Factor", mcp.Description("Parameter Factor")),
+			mcp.WithNumber("number1", mcp.Description("Parameter number1")),
+			mcp.WithNumber("number2", mcp.Description("Parameter number2")),
+			mcp.WithNumber("factor", mcp.Description("Parameter factor")),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "Number1":
+					name = "number1"
+				case "Number2":
+					name = "number2"
+				case "Factor":
+					name = "factor"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
 			req := &CalculateSumRequest{}
-			req.Number1 = mcp.ParseInt32(request, "Number1", 0)
-			req.Number2 = mcp.ParseInt32(request, "Number2", 0)
-			req.Factor = mcp.ParseFloat64(request, "Factor", 0)
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
 			res, err := srv.CalculateSum(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Sum: "+fmt.Sprintf("%v", res.Sum)))
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Product: "+fmt.Sprintf("%v", res.Product)))
-
-			return result, nil
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 	s.AddTool(
 		mcp.NewTool(
 			"CheckStatus",
-			mcp.WithDescription("CheckStatus description"),
+			mcp.WithDescription("CheckStatus demonstrates boolean parameters"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "CheckStatus",
 			}),
-			mcp.WithBoolean("IsActive", mcp.Description("Parameter IsActive")),
-			mcp.WithBoolean("SendNotification", mcp.Description("Parameter SendNotification")),
+			mcp.WithBoolean("isActive", mcp.Description("Parameter isActive")),
+			mcp.WithBoolean("sendNotification", mcp.Description("Parameter sendNotification")),
+			mcp.WithString("priority", mcp.Description("Priority of a status check\n\nValues:\n- PRIORITY_UNSPECIFIED: No priority given\n- PRIORITY_LOW: Checked when convenient\n- PRIORITY_HIGH: Checked right away"), mcp.Enum("PRIORITY_UNSPECIFIED", "PRIORITY_LOW", "PRIORITY_HIGH")),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "is_active", "IsActive":
+					name = "isActive"
+				case "send_notification", "SendNotification":
+					name = "sendNotification"
+				case "Priority":
+					name = "priority"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
+			if err := exampleProtoCheckEnums((&CheckStatusRequest{}).ProtoReflect().Descriptor(), args, ""); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			req := &CheckStatusRequest{}
-			req.IsActive = mcp.ParseBoolean(request, "IsActive", false)
-			req.SendNotification = mcp.ParseBoolean(request, "SendNotification", false)
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
 			res, err := srv.CheckStatus(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Success: "+fmt.Sprintf("%v", res.Success)))
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Message: "+res.Message))
-
-			return result, nil
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 	s.AddTool(
 		mcp.NewTool(
 			"ProcessNames",
-			mcp.WithDescription("ProcessNames description"),
+			mcp.WithDescription("ProcessNames demonstrates array parameters"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "ProcessNames",
 			}),
-			mcp.WithArray("Names", mcp.Description("Parameter Names")),
-			mcp.WithArray("Counts", mcp.Description("Parameter Counts")),
+			mcp.WithArray("names", mcp.Description("Parameter names"), mcp.Items(map[string]interface{}{
+				"type": "string",
+			})),
+			mcp.WithArray("counts", mcp.Description("Parameter counts"), mcp.Items(map[string]interface{}{
+				"type": "integer",
+			})),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			req := &ProcessNamesRequest{}
-			// Handle repeated field Names
-			if arr, ok := request.Params.Arguments["Names"]; ok && arr != nil {
-				if arrValue, ok := arr.([]interface{}); ok {
-					for _, v := range arrValue {
-						if strVal, ok := v.(string); ok {
-							req.Names = append(req.Names, strVal)
-						}
-					}
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "Names":
+					name = "names"
+				case "Counts":
+					name = "counts"
 				}
-			}
-			// Handle repeated field Counts
-			if arr, ok := request.Params.Arguments["Counts"]; ok && arr != nil {
-				if arrValue, ok := arr.([]interface{}); ok {
-					for _, v := range arrValue {
-						if numVal, ok := v.(float64); ok { // JSON numbers are float64
-							req.Counts = append(req.Counts, int32(numVal))
-						}
-					}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
 				}
+				args[name] = value
+			}
+			req := &ProcessNamesRequest{}
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
 			}
 
 			res, err := srv.ProcessNames(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format repeated field
-			if len(res.ProcessedNames) > 0 {
-				arrayStr := "["
-				for i, v := range res.ProcessedNames {
-					if i > 0 {
-						arrayStr += ", "
-					}
-					arrayStr += fmt.Sprintf("%q", v)
+			return mcp.NewToolResultText(string(out)), nil
+		},
+	)
+	s.AddTool(
+		mcp.NewTool(
+			"ComplexOperation",
+			mcp.WithDescription("ComplexOperation demonstrates mixed parameter types"),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title: "ComplexOperation",
+			}),
+			mcp.WithString("operationName", mcp.Description("Parameter operationName")),
+			mcp.WithBoolean("isPriority", mcp.Description("Parameter isPriority")),
+			mcp.WithArray("tags", mcp.Description("Parameter tags"), mcp.Items(map[string]interface{}{
+				"type": "string",
+			})),
+			mcp.WithNumber("timeout", mcp.Description("Parameter timeout")),
+			mcp.WithArray("values", mcp.Description("Parameter values"), mcp.Items(map[string]interface{}{
+				"type": "number",
+			})),
+			mcp.WithObject("labels", mcp.Description("Free-form labels attached to the operation"), mcp.AdditionalProperties(map[string]interface{}{
+				"type": "string",
+			})),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "operation_name", "OperationName":
+					name = "operationName"
+				case "is_priority", "IsPriority":
+					name = "isPriority"
+				case "Tags":
+					name = "tags"
+				case "Timeout":
+					name = "timeout"
+				case "Values":
+					name = "values"
+				case "Labels":
+					name = "labels"
 				}
-				arrayStr += "]"
-				result.Content = append(result.Content, mcp.NewTextContent("ProcessedNames: "+arrayStr))
-			} else {
-				result.Content = append(result.Content, mcp.NewTextContent("ProcessedNames: []"))
-			}
-			// Format repeated field
-			if len(res.ProcessedCounts) > 0 {
-				arrayStr := "["
-				for i, v := range res.ProcessedCounts {
-					if i > 0 {
-						arrayStr += ", "
-					}
-					arrayStr += fmt.Sprintf("%v", v)
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
 				}
-				arrayStr += "]"
-				result.Content = append(result.Content, mcp.NewTextContent("ProcessedCounts: "+arrayStr))
-			} else {
-				result.Content = append(result.Content, mcp.NewTextContent("ProcessedCounts: []"))
+				args[name] = value
+			}
+			req := &ComplexOperationRequest{}
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Summary: "+res.Summary))
 
-			return result, nil
+			res, err := srv.ComplexOperation(ctx, req)
+			if err != nil {
+				return exampleProtoToolError(ctx, err)
+			}
+
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 	s.AddTool(
 		mcp.NewTool(
-			"ComplexOperation",
-			mcp.WithDescription("ComplexOperation description"),
+			"RegisterContact",
+			mcp.WithDescription("RegisterContact demonstrates nested message parameters"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
-				Title: "ComplexOperation",
+				Title: "RegisterContact",
 			}),
-			mcp.WithString("OperationName", mcp.Description("Parameter OperationName")),
-			mcp.WithBoolean("IsPriority", mcp.Description("Parameter IsPriority")),
-			mcp.WithArray("Tags", mcp.Description("Parameter Tags")),
-			mcp.WithNumber("Timeout", mcp.Description("Parameter Timeout")),
-			mcp.WithArray("Values", mcp.Description("Parameter Values")),
+			mcp.WithObject("contact", mcp.Description("Contact is a person with one or more addresses"), mcp.PropertyOption(func(schema map[string]interface{}) { schema["$ref"] = "#/$defs/example.Contact" })),
+			mcp.WithArray("referrers", mcp.Description("Contacts that referred this one"), mcp.Items(map[string]interface{}{
+				"$ref": "#/$defs/example.Contact",
+			})),
+			func(t *mcp.Tool) {
+				t.InputSchema.Defs = map[string]interface{}{
+					"example.Address": map[string]interface{}{
+						"description": "Address is a postal address",
+						"properties": map[string]interface{}{
+							"city": map[string]interface{}{
+								"type": "string",
+							},
+							"postalCode": map[string]interface{}{
+								"type": "string",
+							},
+							"street": map[string]interface{}{
+								"type": "string",
+							},
+						},
+						"type": "object",
+					},
+					"example.Contact": map[string]interface{}{
+						"description": "Contact is a person with one or more addresses",
+						"properties": map[string]interface{}{
+							"home": map[string]interface{}{
+								"$ref": "#/$defs/example.Address",
+							},
+							"name": map[string]interface{}{
+								"type": "string",
+							},
+							"otherAddresses": map[string]interface{}{
+								"description": "Any other known addresses",
+								"items": map[string]interface{}{
+									"$ref": "#/$defs/example.Address",
+								},
+								"type": "array",
+							},
+						},
+						"type": "object",
+					},
+				}
+			},
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			req := &ComplexOperationRequest{}
-			req.OperationName = mcp.ParseString(request, "OperationName", "")
-			req.IsPriority = mcp.ParseBoolean(request, "IsPriority", false)
-			// Handle repeated field Tags
-			if arr, ok := request.Params.Arguments["Tags"]; ok && arr != nil {
-				if arrValue, ok := arr.([]interface{}); ok {
-					for _, v := range arrValue {
-						if strVal, ok := v.(string); ok {
-							req.Tags = append(req.Tags, strVal)
-						}
-					}
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "Contact":
+					name = "contact"
+				case "Referrers":
+					name = "referrers"
 				}
-			}
-			req.Timeout = mcp.ParseInt32(request, "Timeout", 0)
-			// Handle repeated field Values
-			if arr, ok := request.Params.Arguments["Values"]; ok && arr != nil {
-				if arrValue, ok := arr.([]interface{}); ok {
-					for _, v := range arrValue {
-						if numVal, ok := v.(float64); ok {
-							req.Values = append(req.Values, float64(numVal))
-						}
-					}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
 				}
+				args[name] = value
+			}
+			req := &RegisterContactRequest{}
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
 			}
 
-			res, err := srv.ComplexOperation(ctx, req)
+			res, err := srv.RegisterContact(ctx, req)
+			if err != nil {
+				return exampleProtoToolError(ctx, err)
+			}
+
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
 			if err != nil {
 				return nil, err
 			}
+			return mcp.NewToolResultText(string(out)), nil
+		},
+	)
+	s.AddTool(
+		mcp.NewTool(
+			"CountDown",
+			mcp.WithDescription("CountDown demonstrates server streaming, one message per step"),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title: "CountDown",
+			}),
+			mcp.WithNumber("from", mcp.Description("Parameter from")),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "From":
+					name = "from"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
+			req := &CountDownRequest{}
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
-			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Success: "+fmt.Sprintf("%v", res.Success)))
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("OperationId: "+res.OperationId))
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("StatusCode: "+fmt.Sprintf("%v", res.StatusCode)))
-			// Format repeated field
-			if len(res.Results) > 0 {
-				arrayStr := "["
-				for i, v := range res.Results {
-					if i > 0 {
-						arrayStr += ", "
+			// Forward each streamed message as a progress notification when the
+			// client asked for progress, and return them all at the end.
+			var progressToken mcp.ProgressToken
+			if request.Params.Meta != nil {
+				progressToken = request.Params.Meta.ProgressToken
+			}
+			messages := []json.RawMessage{}
+			err = srv.CountDown(ctx, req, func(res *CountDownResponse) error {
+				out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+				if err != nil {
+					return err
+				}
+				messages = append(messages, out)
+				if s := server.ServerFromContext(ctx); s != nil && progressToken != nil {
+					// Notifications are best effort; the messages are part of
+					// the result either way.
+					_ = s.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+						"progressToken": progressToken,
+						"progress":      len(messages),
+						"message":       string(out),
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return exampleProtoToolError(ctx, err)
+			}
+			out, err := json.Marshal(map[string]interface{}{"messages": messages})
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		},
+	)
+	s.AddTool(
+		mcp.NewTool(
+			"SumAll",
+			mcp.WithDescription("SumAll demonstrates client streaming, adding up a batch of sums"),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title: "SumAll",
+			}),
+			mcp.WithArray("items", mcp.Description("CalculateSumRequest messages to stream to the method, in order"), mcp.Items(map[string]interface{}{
+				"description": "CalculateSumRequest has numeric parameters",
+				"properties": map[string]interface{}{
+					"factor": map[string]interface{}{
+						"type": "number",
+					},
+					"number1": map[string]interface{}{
+						"type": "integer",
+					},
+					"number2": map[string]interface{}{
+						"type": "integer",
+					},
+				},
+				"type": "object",
+			})),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			values, ok := request.GetArguments()["items"].([]interface{})
+			if !ok && request.GetArguments()["items"] != nil {
+				return mcp.NewToolResultError("invalid arguments: items must be an array"), nil
+			}
+			items := make([]*CalculateSumRequest, len(values))
+			for i, value := range values {
+				in, ok := value.(map[string]interface{})
+				if !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid items[%d]: must be an object", i)), nil
+				}
+				// Rename the parameters to the proto JSON names, so that the proto
+				// and Go field names are accepted as well.
+				args := make(map[string]interface{}, len(in))
+				for name, value := range in {
+					switch name {
+					case "Number1":
+						name = "number1"
+					case "Number2":
+						name = "number2"
+					case "Factor":
+						name = "factor"
+					}
+					if _, ok := args[name]; ok {
+						return mcp.NewToolResultError(fmt.Sprintf("invalid items[%d]: field %s is given under more than one name", i, name)), nil
 					}
-					arrayStr += fmt.Sprintf("%q", v)
+					args[name] = value
 				}
-				arrayStr += "]"
-				result.Content = append(result.Content, mcp.NewTextContent("Results: "+arrayStr))
-			} else {
-				result.Content = append(result.Content, mcp.NewTextContent("Results: []"))
+				req := &CalculateSumRequest{}
+				data, err := json.Marshal(args)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid items[%d]: %v", i, err)), nil
+				}
+				if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid items[%d]: %v", i, err)), nil
+				}
+				items[i] = req
+			}
+
+			res, err := srv.SumAll(ctx, &exampleServiceMcpServer_SumAllStream{items: items})
+			if err != nil {
+				return exampleProtoToolError(ctx, err)
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Average: "+fmt.Sprintf("%v", res.Average)))
 
-			return result, nil
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 }
@@ -281,61 +563,94 @@ func RegisterMyToolsMcpServer(s *server.MCPServer, srv MyToolsMcpServer) {
 	s.AddTool(
 		mcp.NewTool(
 			"Tool1",
-			mcp.WithDescription("Tool1 description"),
+			mcp.WithDescription("Request and response messages for Tool1"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "Tool1",
 			}),
-			mcp.WithString("Firstname", mcp.Description("Parameter Firstname")),
-			mcp.WithString("Lastname", mcp.Description("Parameter Lastname")),
+			mcp.WithString("firstname", mcp.Description("Parameter firstname")),
+			mcp.WithString("lastname", mcp.Description("Parameter lastname")),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "Firstname":
+					name = "firstname"
+				case "Lastname":
+					name = "lastname"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
 			req := &Tool1Request{}
-			req.Firstname = mcp.ParseString(request, "Firstname", "")
-			req.Lastname = mcp.ParseString(request, "Lastname", "")
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
 			res, err := srv.Tool1(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Fullname: "+res.Fullname))
-
-			return result, nil
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 	s.AddTool(
 		mcp.NewTool(
 			"Tool2",
-			mcp.WithDescription("Tool2 description"),
+			mcp.WithDescription("Request and response messages for Tool2"),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "Tool2",
 			}),
-			mcp.WithString("Name", mcp.Description("Parameter Name")),
+			mcp.WithString("name", mcp.Description("Parameter name")),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "Name":
+					name = "name"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
 			req := &Tool2Request{}
-			req.Name = mcp.ParseString(request, "Name", "")
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
 			res, err := srv.Tool2(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("Result: "+res.Result))
-
-			return result, nil
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 	s.AddTool(
@@ -345,41 +660,142 @@ func RegisterMyToolsMcpServer(s *server.MCPServer, srv MyToolsMcpServer) {
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title: "Tool3",
 			}),
-			mcp.WithString("WallaceFavoriteFood", mcp.Description("Parameter WallaceFavoriteFood")),
+			mcp.WithString("wallaceFavoriteFood", mcp.Description("Parameter wallaceFavoriteFood")),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			in := request.GetArguments()
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				switch name {
+				case "wallace_favorite_food", "WallaceFavoriteFood":
+					name = "wallaceFavoriteFood"
+				}
+				if _, ok := args[name]; ok {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is given under more than one name", name)), nil
+				}
+				args[name] = value
+			}
 			req := &Tool3Request{}
-			req.WallaceFavoriteFood = mcp.ParseString(request, "WallaceFavoriteFood", "")
+			data, err := json.Marshal(args)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: false}).Unmarshal(data, req); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
+			}
 
 			res, err := srv.Tool3(ctx, req)
 			if err != nil {
-				return nil, err
+				return exampleProtoToolError(ctx, err)
 			}
 
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
-				IsError: false,
+			out, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
 			}
-			// Format non-repeated field
-			result.Content = append(result.Content, mcp.NewTextContent("HisFavoriteFood: "+res.HisFavoriteFood))
-
-			return result, nil
+			return mcp.NewToolResultText(string(out)), nil
 		},
 	)
 }
 
-func ServeStdio(
-	name,
-	version string,
+// exampleProtoStatus returns the gRPC status of an error as google.rpc.Status
+// JSON, leaving out the details when some can't be encoded
+func exampleProtoStatus(err error) json.RawMessage {
+	st := status.Convert(err).Proto()
+	out, merr := protojson.Marshal(st)
+	if merr != nil {
+		st.Details = nil
+		out, _ = protojson.Marshal(st)
+	}
+	return out
+}
+
+// exampleProtoToolError converts an error returned by a service to a tool
+// error result carrying its gRPC status, so that the model sees it. Errors of
+// canceled tool calls remain protocol errors.
+func exampleProtoToolError(ctx context.Context, err error) (*mcp.CallToolResult, error) {
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	st := status.Convert(err)
+	result := mcp.NewToolResultError(st.Code().String() + ": " + st.Message())
+	result.StructuredContent = exampleProtoStatus(err)
+	return result, nil
+}
+
+// exampleProtoCheckEnums checks that the enums in the JSON value of a
+// message, at any depth, are names of their values. Errors
+// name the path of the value, which follows prefix.
+func exampleProtoCheckEnums(md protoreflect.MessageDescriptor, value interface{}, prefix string) error {
+	fields, ok := value.(map[string]interface{})
+	if !ok || md.ParentFile().Package() == "google.protobuf" {
+		return nil
+	}
+	for name, value := range fields {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(name))
+		}
+		if fd == nil {
+			continue
+		}
+		// Check every value of the field, keyed by its path suffix
+		values := map[string]interface{}{"": value}
+		vd := fd
+		if fd.IsMap() {
+			vd = fd.MapValue()
+			entries, _ := value.(map[string]interface{})
+			values = make(map[string]interface{}, len(entries))
+			for key, value := range entries {
+				values["["+strconv.Quote(key)+"]"] = value
+			}
+		} else if fd.IsList() {
+			list, _ := value.([]interface{})
+			values = make(map[string]interface{}, len(list))
+			for i, value := range list {
+				values["["+strconv.Itoa(i)+"]"] = value
+			}
+		}
+		for suffix, value := range values {
+			path := prefix + fd.JSONName() + suffix
+			switch vd.Kind() {
+			case protoreflect.EnumKind:
+				ed := vd.Enum()
+				known := true
+				switch value := value.(type) {
+				case string:
+					known = ed.Values().ByName(protoreflect.Name(value)) != nil
+				case float64:
+					known = false
+				}
+				if !known {
+					names := make([]string, ed.Values().Len())
+					for i := range names {
+						names[i] = string(ed.Values().Get(i).Name())
+					}
+					text := fmt.Sprint(value)
+					if s, ok := value.(string); ok {
+						text = strconv.Quote(s)
+					}
+					return fmt.Errorf("invalid value %s for %s: must be one of %s", text, path, strings.Join(names, ", "))
+				}
+			case protoreflect.MessageKind, protoreflect.GroupKind:
+				if err := exampleProtoCheckEnums(vd.Message(), value, path+"."); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func RegisterExampleProtoMcpServers(
+	s *server.MCPServer,
 	srvExampleService ExampleServiceMcpServer,
 	srvMyTools MyToolsMcpServer,
-) error {
-	s := server.NewMCPServer(name, version,
-		server.WithToolCapabilities(true),
-		server.WithLogging(),
-	)
+) {
 	RegisterExampleServiceMcpServer(s, srvExampleService)
 	RegisterMyToolsMcpServer(s, srvMyTools)
-	return server.ServeStdio(s)
 }
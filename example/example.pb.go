@@ -9,6 +9,7 @@ package example
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,11 +22,66 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Priority of a status check
+type Priority int32
+
+const (
+	// No priority given
+	Priority_PRIORITY_UNSPECIFIED Priority = 0
+	// Checked when convenient
+	Priority_PRIORITY_LOW Priority = 1
+	// Checked right away
+	Priority_PRIORITY_HIGH Priority = 2
+)
+
+// Enum value maps for Priority.
+var (
+	Priority_name = map[int32]string{
+		0: "PRIORITY_UNSPECIFIED",
+		1: "PRIORITY_LOW",
+		2: "PRIORITY_HIGH",
+	}
+	Priority_value = map[string]int32{
+		"PRIORITY_UNSPECIFIED": 0,
+		"PRIORITY_LOW":         1,
+		"PRIORITY_HIGH":        2,
+	}
+)
+
+func (x Priority) Enum() *Priority {
+	p := new(Priority)
+	*p = x
+	return p
+}
+
+func (x Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_example_proto_enumTypes[0].Descriptor()
+}
+
+func (Priority) Type() protoreflect.EnumType {
+	return &file_example_proto_enumTypes[0]
+}
+
+func (x Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Priority.Descriptor instead.
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{0}
+}
+
 // GreetPersonRequest has string parameters
 type GreetPersonRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	FirstName     string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
-	LastName      string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	FirstName string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName  string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	// Preferred name, used instead of the first name when set
+	Nickname      *string `protobuf:"bytes,3,opt,name=nickname,proto3,oneof" json:"nickname,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,6 +130,13 @@ func (x *GreetPersonRequest) GetLastName() string {
 	return ""
 }
 
+func (x *GreetPersonRequest) GetNickname() string {
+	if x != nil && x.Nickname != nil {
+		return *x.Nickname
+	}
+	return ""
+}
+
 // GreetPersonResponse returns a string
 type GreetPersonResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -233,11 +296,12 @@ func (x *CalculateSumResponse) GetProduct() float64 {
 	return 0
 }
 
-// CheckStatusRequest has boolean parameters
+// CheckStatusRequest has boolean and enum parameters
 type CheckStatusRequest struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	IsActive         bool                   `protobuf:"varint,1,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
 	SendNotification bool                   `protobuf:"varint,2,opt,name=send_notification,json=sendNotification,proto3" json:"send_notification,omitempty"`
+	Priority         Priority               `protobuf:"varint,3,opt,name=priority,proto3,enum=example.Priority" json:"priority,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -286,6 +350,13 @@ func (x *CheckStatusRequest) GetSendNotification() bool {
 	return false
 }
 
+func (x *CheckStatusRequest) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_PRIORITY_UNSPECIFIED
+}
+
 // CheckStatusResponse returns boolean and string results
 type CheckStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -461,6 +532,8 @@ type ComplexOperationRequest struct {
 	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
 	Timeout       int32                  `protobuf:"varint,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
 	Values        []float64              `protobuf:"fixed64,5,rep,packed,name=values,proto3" json:"values,omitempty"`
+	// Free-form labels attached to the operation
+	Labels        map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -530,14 +603,25 @@ func (x *ComplexOperationRequest) GetValues() []float64 {
 	return nil
 }
 
+func (x *ComplexOperationRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 // ComplexOperationResponse demonstrates mixed return types
 type ComplexOperationResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	OperationId   string                 `protobuf:"bytes,2,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
-	StatusCode    int32                  `protobuf:"varint,3,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
-	Results       []string               `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
-	Average       float64                `protobuf:"fixed64,5,opt,name=average,proto3" json:"average,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Success     bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	OperationId string                 `protobuf:"bytes,2,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	StatusCode  int32                  `protobuf:"varint,3,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Results     []string               `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
+	Average     float64                `protobuf:"fixed64,5,opt,name=average,proto3" json:"average,omitempty"`
+	// Number of times each tag was seen
+	TagCounts map[string]int32 `protobuf:"bytes,6,rep,name=tag_counts,json=tagCounts,proto3" json:"tag_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// When the operation completed
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -607,6 +691,341 @@ func (x *ComplexOperationResponse) GetAverage() float64 {
 	return 0
 }
 
+func (x *ComplexOperationResponse) GetTagCounts() map[string]int32 {
+	if x != nil {
+		return x.TagCounts
+	}
+	return nil
+}
+
+func (x *ComplexOperationResponse) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+// Address is a postal address
+type Address struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Street        string                 `protobuf:"bytes,1,opt,name=street,proto3" json:"street,omitempty"`
+	City          string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	PostalCode    string                 `protobuf:"bytes,3,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_example_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_example_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Address) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *Address) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Address) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+// Contact is a person with one or more addresses
+type Contact struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Where the contact lives
+	Home *Address `protobuf:"bytes,2,opt,name=home,proto3" json:"home,omitempty"`
+	// Any other known addresses
+	OtherAddresses []*Address `protobuf:"bytes,3,rep,name=other_addresses,json=otherAddresses,proto3" json:"other_addresses,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Contact) Reset() {
+	*x = Contact{}
+	mi := &file_example_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Contact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contact) ProtoMessage() {}
+
+func (x *Contact) ProtoReflect() protoreflect.Message {
+	mi := &file_example_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contact.ProtoReflect.Descriptor instead.
+func (*Contact) Descriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Contact) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Contact) GetHome() *Address {
+	if x != nil {
+		return x.Home
+	}
+	return nil
+}
+
+func (x *Contact) GetOtherAddresses() []*Address {
+	if x != nil {
+		return x.OtherAddresses
+	}
+	return nil
+}
+
+// RegisterContactRequest demonstrates nested messages
+type RegisterContactRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Contact *Contact               `protobuf:"bytes,1,opt,name=contact,proto3" json:"contact,omitempty"`
+	// Contacts that referred this one
+	Referrers     []*Contact `protobuf:"bytes,2,rep,name=referrers,proto3" json:"referrers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterContactRequest) Reset() {
+	*x = RegisterContactRequest{}
+	mi := &file_example_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterContactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterContactRequest) ProtoMessage() {}
+
+func (x *RegisterContactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_example_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterContactRequest.ProtoReflect.Descriptor instead.
+func (*RegisterContactRequest) Descriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RegisterContactRequest) GetContact() *Contact {
+	if x != nil {
+		return x.Contact
+	}
+	return nil
+}
+
+func (x *RegisterContactRequest) GetReferrers() []*Contact {
+	if x != nil {
+		return x.Referrers
+	}
+	return nil
+}
+
+// RegisterContactResponse returns the registered contact
+type RegisterContactResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContactId     string                 `protobuf:"bytes,1,opt,name=contact_id,json=contactId,proto3" json:"contact_id,omitempty"`
+	Contact       *Contact               `protobuf:"bytes,2,opt,name=contact,proto3" json:"contact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterContactResponse) Reset() {
+	*x = RegisterContactResponse{}
+	mi := &file_example_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterContactResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterContactResponse) ProtoMessage() {}
+
+func (x *RegisterContactResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_example_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterContactResponse.ProtoReflect.Descriptor instead.
+func (*RegisterContactResponse) Descriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RegisterContactResponse) GetContactId() string {
+	if x != nil {
+		return x.ContactId
+	}
+	return ""
+}
+
+func (x *RegisterContactResponse) GetContact() *Contact {
+	if x != nil {
+		return x.Contact
+	}
+	return nil
+}
+
+// CountDownRequest sets where the count down starts
+type CountDownRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int32                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountDownRequest) Reset() {
+	*x = CountDownRequest{}
+	mi := &file_example_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountDownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountDownRequest) ProtoMessage() {}
+
+func (x *CountDownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_example_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountDownRequest.ProtoReflect.Descriptor instead.
+func (*CountDownRequest) Descriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CountDownRequest) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+// CountDownResponse is one step of the count down
+type CountDownResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Remaining     int32                  `protobuf:"varint,1,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountDownResponse) Reset() {
+	*x = CountDownResponse{}
+	mi := &file_example_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountDownResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountDownResponse) ProtoMessage() {}
+
+func (x *CountDownResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_example_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountDownResponse.ProtoReflect.Descriptor instead.
+func (*CountDownResponse) Descriptor() ([]byte, []int) {
+	return file_example_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CountDownResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
 // Request and response messages for Tool1
 type Tool1Request struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -618,7 +1037,7 @@ type Tool1Request struct {
 
 func (x *Tool1Request) Reset() {
 	*x = Tool1Request{}
-	mi := &file_example_proto_msgTypes[10]
+	mi := &file_example_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -630,7 +1049,7 @@ func (x *Tool1Request) String() string {
 func (*Tool1Request) ProtoMessage() {}
 
 func (x *Tool1Request) ProtoReflect() protoreflect.Message {
-	mi := &file_example_proto_msgTypes[10]
+	mi := &file_example_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -643,7 +1062,7 @@ func (x *Tool1Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool1Request.ProtoReflect.Descriptor instead.
 func (*Tool1Request) Descriptor() ([]byte, []int) {
-	return file_example_proto_rawDescGZIP(), []int{10}
+	return file_example_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *Tool1Request) GetFirstname() string {
@@ -669,7 +1088,7 @@ type Tool1Response struct {
 
 func (x *Tool1Response) Reset() {
 	*x = Tool1Response{}
-	mi := &file_example_proto_msgTypes[11]
+	mi := &file_example_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -681,7 +1100,7 @@ func (x *Tool1Response) String() string {
 func (*Tool1Response) ProtoMessage() {}
 
 func (x *Tool1Response) ProtoReflect() protoreflect.Message {
-	mi := &file_example_proto_msgTypes[11]
+	mi := &file_example_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -694,7 +1113,7 @@ func (x *Tool1Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool1Response.ProtoReflect.Descriptor instead.
 func (*Tool1Response) Descriptor() ([]byte, []int) {
-	return file_example_proto_rawDescGZIP(), []int{11}
+	return file_example_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *Tool1Response) GetFullname() string {
@@ -714,7 +1133,7 @@ type Tool2Request struct {
 
 func (x *Tool2Request) Reset() {
 	*x = Tool2Request{}
-	mi := &file_example_proto_msgTypes[12]
+	mi := &file_example_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -726,7 +1145,7 @@ func (x *Tool2Request) String() string {
 func (*Tool2Request) ProtoMessage() {}
 
 func (x *Tool2Request) ProtoReflect() protoreflect.Message {
-	mi := &file_example_proto_msgTypes[12]
+	mi := &file_example_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,7 +1158,7 @@ func (x *Tool2Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool2Request.ProtoReflect.Descriptor instead.
 func (*Tool2Request) Descriptor() ([]byte, []int) {
-	return file_example_proto_rawDescGZIP(), []int{12}
+	return file_example_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *Tool2Request) GetName() string {
@@ -758,7 +1177,7 @@ type Tool2Response struct {
 
 func (x *Tool2Response) Reset() {
 	*x = Tool2Response{}
-	mi := &file_example_proto_msgTypes[13]
+	mi := &file_example_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -770,7 +1189,7 @@ func (x *Tool2Response) String() string {
 func (*Tool2Response) ProtoMessage() {}
 
 func (x *Tool2Response) ProtoReflect() protoreflect.Message {
-	mi := &file_example_proto_msgTypes[13]
+	mi := &file_example_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -783,7 +1202,7 @@ func (x *Tool2Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool2Response.ProtoReflect.Descriptor instead.
 func (*Tool2Response) Descriptor() ([]byte, []int) {
-	return file_example_proto_rawDescGZIP(), []int{13}
+	return file_example_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *Tool2Response) GetResult() string {
@@ -802,7 +1221,7 @@ type Tool3Request struct {
 
 func (x *Tool3Request) Reset() {
 	*x = Tool3Request{}
-	mi := &file_example_proto_msgTypes[14]
+	mi := &file_example_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -814,7 +1233,7 @@ func (x *Tool3Request) String() string {
 func (*Tool3Request) ProtoMessage() {}
 
 func (x *Tool3Request) ProtoReflect() protoreflect.Message {
-	mi := &file_example_proto_msgTypes[14]
+	mi := &file_example_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -827,7 +1246,7 @@ func (x *Tool3Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool3Request.ProtoReflect.Descriptor instead.
 func (*Tool3Request) Descriptor() ([]byte, []int) {
-	return file_example_proto_rawDescGZIP(), []int{14}
+	return file_example_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *Tool3Request) GetWallaceFavoriteFood() string {
@@ -846,7 +1265,7 @@ type Tool3Response struct {
 
 func (x *Tool3Response) Reset() {
 	*x = Tool3Response{}
-	mi := &file_example_proto_msgTypes[15]
+	mi := &file_example_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -858,7 +1277,7 @@ func (x *Tool3Response) String() string {
 func (*Tool3Response) ProtoMessage() {}
 
 func (x *Tool3Response) ProtoReflect() protoreflect.Message {
-	mi := &file_example_proto_msgTypes[15]
+	mi := &file_example_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -871,7 +1290,7 @@ func (x *Tool3Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tool3Response.ProtoReflect.Descriptor instead.
 func (*Tool3Response) Descriptor() ([]byte, []int) {
-	return file_example_proto_rawDescGZIP(), []int{15}
+	return file_example_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *Tool3Response) GetHisFavoriteFood() string {
@@ -885,11 +1304,13 @@ var File_example_proto protoreflect.FileDescriptor
 
 const file_example_proto_rawDesc = "" +
 	"\n" +
-	"\rexample.proto\x12\aexample\"P\n" +
+	"\rexample.proto\x12\aexample\x1a\x1fgoogle/protobuf/timestamp.proto\"~\n" +
 	"\x12GreetPersonRequest\x12\x1d\n" +
 	"\n" +
 	"first_name\x18\x01 \x01(\tR\tfirstName\x12\x1b\n" +
-	"\tlast_name\x18\x02 \x01(\tR\blastName\"1\n" +
+	"\tlast_name\x18\x02 \x01(\tR\blastName\x12\x1f\n" +
+	"\bnickname\x18\x03 \x01(\tH\x00R\bnickname\x88\x01\x01B\v\n" +
+	"\t_nickname\"1\n" +
 	"\x13GreetPersonResponse\x12\x1a\n" +
 	"\bgreeting\x18\x01 \x01(\tR\bgreeting\"a\n" +
 	"\x13CalculateSumRequest\x12\x18\n" +
@@ -898,10 +1319,11 @@ const file_example_proto_rawDesc = "" +
 	"\x06factor\x18\x03 \x01(\x01R\x06factor\"B\n" +
 	"\x14CalculateSumResponse\x12\x10\n" +
 	"\x03sum\x18\x01 \x01(\x05R\x03sum\x12\x18\n" +
-	"\aproduct\x18\x02 \x01(\x01R\aproduct\"^\n" +
+	"\aproduct\x18\x02 \x01(\x01R\aproduct\"\x8d\x01\n" +
 	"\x12CheckStatusRequest\x12\x1b\n" +
 	"\tis_active\x18\x01 \x01(\bR\bisActive\x12+\n" +
-	"\x11send_notification\x18\x02 \x01(\bR\x10sendNotification\"I\n" +
+	"\x11send_notification\x18\x02 \x01(\bR\x10sendNotification\x12-\n" +
+	"\bpriority\x18\x03 \x01(\x0e2\x11.example.PriorityR\bpriority\"I\n" +
 	"\x13CheckStatusResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"C\n" +
@@ -911,21 +1333,51 @@ const file_example_proto_rawDesc = "" +
 	"\x14ProcessNamesResponse\x12'\n" +
 	"\x0fprocessed_names\x18\x01 \x03(\tR\x0eprocessedNames\x12)\n" +
 	"\x10processed_counts\x18\x02 \x03(\x05R\x0fprocessedCounts\x12\x18\n" +
-	"\asummary\x18\x03 \x01(\tR\asummary\"\xa7\x01\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\"\xa8\x02\n" +
 	"\x17ComplexOperationRequest\x12%\n" +
 	"\x0eoperation_name\x18\x01 \x01(\tR\roperationName\x12\x1f\n" +
 	"\vis_priority\x18\x02 \x01(\bR\n" +
 	"isPriority\x12\x12\n" +
 	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x18\n" +
 	"\atimeout\x18\x04 \x01(\x05R\atimeout\x12\x16\n" +
-	"\x06values\x18\x05 \x03(\x01R\x06values\"\xac\x01\n" +
+	"\x06values\x18\x05 \x03(\x01R\x06values\x12D\n" +
+	"\x06labels\x18\x06 \x03(\v2,.example.ComplexOperationRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xfa\x02\n" +
 	"\x18ComplexOperationResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
 	"\foperation_id\x18\x02 \x01(\tR\voperationId\x12\x1f\n" +
 	"\vstatus_code\x18\x03 \x01(\x05R\n" +
 	"statusCode\x12\x18\n" +
 	"\aresults\x18\x04 \x03(\tR\aresults\x12\x18\n" +
-	"\aaverage\x18\x05 \x01(\x01R\aaverage\"H\n" +
+	"\aaverage\x18\x05 \x01(\x01R\aaverage\x12O\n" +
+	"\n" +
+	"tag_counts\x18\x06 \x03(\v20.example.ComplexOperationResponse.TagCountsEntryR\ttagCounts\x12=\n" +
+	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x1a<\n" +
+	"\x0eTagCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"V\n" +
+	"\aAddress\x12\x16\n" +
+	"\x06street\x18\x01 \x01(\tR\x06street\x12\x12\n" +
+	"\x04city\x18\x02 \x01(\tR\x04city\x12\x1f\n" +
+	"\vpostal_code\x18\x03 \x01(\tR\n" +
+	"postalCode\"~\n" +
+	"\aContact\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12$\n" +
+	"\x04home\x18\x02 \x01(\v2\x10.example.AddressR\x04home\x129\n" +
+	"\x0fother_addresses\x18\x03 \x03(\v2\x10.example.AddressR\x0eotherAddresses\"t\n" +
+	"\x16RegisterContactRequest\x12*\n" +
+	"\acontact\x18\x01 \x01(\v2\x10.example.ContactR\acontact\x12.\n" +
+	"\treferrers\x18\x02 \x03(\v2\x10.example.ContactR\treferrers\"d\n" +
+	"\x17RegisterContactResponse\x12\x1d\n" +
+	"\n" +
+	"contact_id\x18\x01 \x01(\tR\tcontactId\x12*\n" +
+	"\acontact\x18\x02 \x01(\v2\x10.example.ContactR\acontact\"&\n" +
+	"\x10CountDownRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x05R\x04from\"1\n" +
+	"\x11CountDownResponse\x12\x1c\n" +
+	"\tremaining\x18\x01 \x01(\x05R\tremaining\"H\n" +
 	"\fTool1Request\x12\x1c\n" +
 	"\tfirstname\x18\x01 \x01(\tR\tfirstname\x12\x1a\n" +
 	"\blastname\x18\x02 \x01(\tR\blastname\"+\n" +
@@ -938,13 +1390,20 @@ const file_example_proto_rawDesc = "" +
 	"\fTool3Request\x122\n" +
 	"\x15wallace_favorite_food\x18\x01 \x01(\tR\x13wallaceFavoriteFood\";\n" +
 	"\rTool3Response\x12*\n" +
-	"\x11his_favorite_food\x18\x01 \x01(\tR\x0fhisFavoriteFood2\x97\x03\n" +
+	"\x11his_favorite_food\x18\x01 \x01(\tR\x0fhisFavoriteFood*I\n" +
+	"\bPriority\x12\x18\n" +
+	"\x14PRIORITY_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fPRIORITY_LOW\x10\x01\x12\x11\n" +
+	"\rPRIORITY_HIGH\x10\x022\xfc\x04\n" +
 	"\x0eExampleService\x12H\n" +
 	"\vGreetPerson\x12\x1b.example.GreetPersonRequest\x1a\x1c.example.GreetPersonResponse\x12K\n" +
 	"\fCalculateSum\x12\x1c.example.CalculateSumRequest\x1a\x1d.example.CalculateSumResponse\x12H\n" +
 	"\vCheckStatus\x12\x1b.example.CheckStatusRequest\x1a\x1c.example.CheckStatusResponse\x12K\n" +
 	"\fProcessNames\x12\x1c.example.ProcessNamesRequest\x1a\x1d.example.ProcessNamesResponse\x12W\n" +
-	"\x10ComplexOperation\x12 .example.ComplexOperationRequest\x1a!.example.ComplexOperationResponse2\xb1\x01\n" +
+	"\x10ComplexOperation\x12 .example.ComplexOperationRequest\x1a!.example.ComplexOperationResponse\x12T\n" +
+	"\x0fRegisterContact\x12\x1f.example.RegisterContactRequest\x1a .example.RegisterContactResponse\x12D\n" +
+	"\tCountDown\x12\x19.example.CountDownRequest\x1a\x1a.example.CountDownResponse0\x01\x12G\n" +
+	"\x06SumAll\x12\x1c.example.CalculateSumRequest\x1a\x1d.example.CalculateSumResponse(\x012\xb1\x01\n" +
 	"\aMyTools\x126\n" +
 	"\x05Tool1\x12\x15.example.Tool1Request\x1a\x16.example.Tool1Response\x126\n" +
 	"\x05Tool2\x12\x15.example.Tool2Request\x1a\x16.example.Tool2Response\x126\n" +
@@ -963,47 +1422,73 @@ func file_example_proto_rawDescGZIP() []byte {
 	return file_example_proto_rawDescData
 }
 
-var file_example_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_example_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_example_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_example_proto_goTypes = []any{
-	(*GreetPersonRequest)(nil),       // 0: example.GreetPersonRequest
-	(*GreetPersonResponse)(nil),      // 1: example.GreetPersonResponse
-	(*CalculateSumRequest)(nil),      // 2: example.CalculateSumRequest
-	(*CalculateSumResponse)(nil),     // 3: example.CalculateSumResponse
-	(*CheckStatusRequest)(nil),       // 4: example.CheckStatusRequest
-	(*CheckStatusResponse)(nil),      // 5: example.CheckStatusResponse
-	(*ProcessNamesRequest)(nil),      // 6: example.ProcessNamesRequest
-	(*ProcessNamesResponse)(nil),     // 7: example.ProcessNamesResponse
-	(*ComplexOperationRequest)(nil),  // 8: example.ComplexOperationRequest
-	(*ComplexOperationResponse)(nil), // 9: example.ComplexOperationResponse
-	(*Tool1Request)(nil),             // 10: example.Tool1Request
-	(*Tool1Response)(nil),            // 11: example.Tool1Response
-	(*Tool2Request)(nil),             // 12: example.Tool2Request
-	(*Tool2Response)(nil),            // 13: example.Tool2Response
-	(*Tool3Request)(nil),             // 14: example.Tool3Request
-	(*Tool3Response)(nil),            // 15: example.Tool3Response
+	(Priority)(0),                    // 0: example.Priority
+	(*GreetPersonRequest)(nil),       // 1: example.GreetPersonRequest
+	(*GreetPersonResponse)(nil),      // 2: example.GreetPersonResponse
+	(*CalculateSumRequest)(nil),      // 3: example.CalculateSumRequest
+	(*CalculateSumResponse)(nil),     // 4: example.CalculateSumResponse
+	(*CheckStatusRequest)(nil),       // 5: example.CheckStatusRequest
+	(*CheckStatusResponse)(nil),      // 6: example.CheckStatusResponse
+	(*ProcessNamesRequest)(nil),      // 7: example.ProcessNamesRequest
+	(*ProcessNamesResponse)(nil),     // 8: example.ProcessNamesResponse
+	(*ComplexOperationRequest)(nil),  // 9: example.ComplexOperationRequest
+	(*ComplexOperationResponse)(nil), // 10: example.ComplexOperationResponse
+	(*Address)(nil),                  // 11: example.Address
+	(*Contact)(nil),                  // 12: example.Contact
+	(*RegisterContactRequest)(nil),   // 13: example.RegisterContactRequest
+	(*RegisterContactResponse)(nil),  // 14: example.RegisterContactResponse
+	(*CountDownRequest)(nil),         // 15: example.CountDownRequest
+	(*CountDownResponse)(nil),        // 16: example.CountDownResponse
+	(*Tool1Request)(nil),             // 17: example.Tool1Request
+	(*Tool1Response)(nil),            // 18: example.Tool1Response
+	(*Tool2Request)(nil),             // 19: example.Tool2Request
+	(*Tool2Response)(nil),            // 20: example.Tool2Response
+	(*Tool3Request)(nil),             // 21: example.Tool3Request
+	(*Tool3Response)(nil),            // 22: example.Tool3Response
+	nil,                              // 23: example.ComplexOperationRequest.LabelsEntry
+	nil,                              // 24: example.ComplexOperationResponse.TagCountsEntry
+	(*timestamppb.Timestamp)(nil),    // 25: google.protobuf.Timestamp
 }
 var file_example_proto_depIdxs = []int32{
-	0,  // 0: example.ExampleService.GreetPerson:input_type -> example.GreetPersonRequest
-	2,  // 1: example.ExampleService.CalculateSum:input_type -> example.CalculateSumRequest
-	4,  // 2: example.ExampleService.CheckStatus:input_type -> example.CheckStatusRequest
-	6,  // 3: example.ExampleService.ProcessNames:input_type -> example.ProcessNamesRequest
-	8,  // 4: example.ExampleService.ComplexOperation:input_type -> example.ComplexOperationRequest
-	10, // 5: example.MyTools.Tool1:input_type -> example.Tool1Request
-	12, // 6: example.MyTools.Tool2:input_type -> example.Tool2Request
-	14, // 7: example.MyTools.Tool3:input_type -> example.Tool3Request
-	1,  // 8: example.ExampleService.GreetPerson:output_type -> example.GreetPersonResponse
-	3,  // 9: example.ExampleService.CalculateSum:output_type -> example.CalculateSumResponse
-	5,  // 10: example.ExampleService.CheckStatus:output_type -> example.CheckStatusResponse
-	7,  // 11: example.ExampleService.ProcessNames:output_type -> example.ProcessNamesResponse
-	9,  // 12: example.ExampleService.ComplexOperation:output_type -> example.ComplexOperationResponse
-	11, // 13: example.MyTools.Tool1:output_type -> example.Tool1Response
-	13, // 14: example.MyTools.Tool2:output_type -> example.Tool2Response
-	15, // 15: example.MyTools.Tool3:output_type -> example.Tool3Response
-	8,  // [8:16] is the sub-list for method output_type
-	0,  // [0:8] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	0,  // 0: example.CheckStatusRequest.priority:type_name -> example.Priority
+	23, // 1: example.ComplexOperationRequest.labels:type_name -> example.ComplexOperationRequest.LabelsEntry
+	24, // 2: example.ComplexOperationResponse.tag_counts:type_name -> example.ComplexOperationResponse.TagCountsEntry
+	25, // 3: example.ComplexOperationResponse.completed_at:type_name -> google.protobuf.Timestamp
+	11, // 4: example.Contact.home:type_name -> example.Address
+	11, // 5: example.Contact.other_addresses:type_name -> example.Address
+	12, // 6: example.RegisterContactRequest.contact:type_name -> example.Contact
+	12, // 7: example.RegisterContactRequest.referrers:type_name -> example.Contact
+	12, // 8: example.RegisterContactResponse.contact:type_name -> example.Contact
+	1,  // 9: example.ExampleService.GreetPerson:input_type -> example.GreetPersonRequest
+	3,  // 10: example.ExampleService.CalculateSum:input_type -> example.CalculateSumRequest
+	5,  // 11: example.ExampleService.CheckStatus:input_type -> example.CheckStatusRequest
+	7,  // 12: example.ExampleService.ProcessNames:input_type -> example.ProcessNamesRequest
+	9,  // 13: example.ExampleService.ComplexOperation:input_type -> example.ComplexOperationRequest
+	13, // 14: example.ExampleService.RegisterContact:input_type -> example.RegisterContactRequest
+	15, // 15: example.ExampleService.CountDown:input_type -> example.CountDownRequest
+	3,  // 16: example.ExampleService.SumAll:input_type -> example.CalculateSumRequest
+	17, // 17: example.MyTools.Tool1:input_type -> example.Tool1Request
+	19, // 18: example.MyTools.Tool2:input_type -> example.Tool2Request
+	21, // 19: example.MyTools.Tool3:input_type -> example.Tool3Request
+	2,  // 20: example.ExampleService.GreetPerson:output_type -> example.GreetPersonResponse
+	4,  // 21: example.ExampleService.CalculateSum:output_type -> example.CalculateSumResponse
+	6,  // 22: example.ExampleService.CheckStatus:output_type -> example.CheckStatusResponse
+	8,  // 23: example.ExampleService.ProcessNames:output_type -> example.ProcessNamesResponse
+	10, // 24: example.ExampleService.ComplexOperation:output_type -> example.ComplexOperationResponse
+	14, // 25: example.ExampleService.RegisterContact:output_type -> example.RegisterContactResponse
+	16, // 26: example.ExampleService.CountDown:output_type -> example.CountDownResponse
+	4,  // 27: example.ExampleService.SumAll:output_type -> example.CalculateSumResponse
+	18, // 28: example.MyTools.Tool1:output_type -> example.Tool1Response
+	20, // 29: example.MyTools.Tool2:output_type -> example.Tool2Response
+	22, // 30: example.MyTools.Tool3:output_type -> example.Tool3Response
+	20, // [20:31] is the sub-list for method output_type
+	9,  // [9:20] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_example_proto_init() }
@@ -1011,18 +1496,20 @@ func file_example_proto_init() {
 	if File_example_proto != nil {
 		return
 	}
+	file_example_proto_msgTypes[0].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_example_proto_rawDesc), len(file_example_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      1,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
 		GoTypes:           file_example_proto_goTypes,
 		DependencyIndexes: file_example_proto_depIdxs,
+		EnumInfos:         file_example_proto_enumTypes,
 		MessageInfos:      file_example_proto_msgTypes,
 	}.Build()
 	File_example_proto = out.File
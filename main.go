@@ -4,8 +4,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
+	"unicode"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -14,6 +18,28 @@ import (
 
 const version = "0.1.0"
 
+// Go packages referenced by the generated code
+const (
+	contextPackage       = protogen.GoImportPath("context")
+	errorsPackage        = protogen.GoImportPath("errors")
+	fmtPackage           = protogen.GoImportPath("fmt")
+	mathPackage          = protogen.GoImportPath("math")
+	ioPackage            = protogen.GoImportPath("io")
+	strconvPackage       = protogen.GoImportPath("strconv")
+	syncPackage          = protogen.GoImportPath("sync")
+	timePackage          = protogen.GoImportPath("time")
+	jsonPackage          = protogen.GoImportPath("encoding/json")
+	base64Package        = protogen.GoImportPath("encoding/base64")
+	stringsPackage       = protogen.GoImportPath("strings")
+	protoreflectPackage  = protogen.GoImportPath("google.golang.org/protobuf/reflect/protoreflect")
+	mcpPackage           = protogen.GoImportPath("github.com/mark3labs/mcp-go/mcp")
+	serverPackage        = protogen.GoImportPath("github.com/mark3labs/mcp-go/server")
+	protojsonPackage     = protogen.GoImportPath("google.golang.org/protobuf/encoding/protojson")
+	protovalidatePackage = protogen.GoImportPath("buf.build/go/protovalidate")
+	statusPackage        = protogen.GoImportPath("google.golang.org/grpc/status")
+	errdetailsPackage    = protogen.GoImportPath("google.golang.org/genproto/googleapis/rpc/errdetails")
+)
+
 func main() {
 	flagVersion := flag.Bool("version", false, "Print the version and exit")
 	flag.Parse()
@@ -23,60 +49,540 @@ func main() {
 		os.Exit(0)
 	}
 
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
-		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-		for _, file := range gen.Files {
-			if !file.Generate {
-				continue
+	var cfg config
+	flags := cfg.flags()
+	var paramErr error
+
+	protogen.Options{
+		// Parameter errors are reported through the plugin response, so that
+		// protoc and buf show them like any other generation error.
+		ParamFunc: func(name, value string) error {
+			if err := setParam(flags, name, value); err != nil && paramErr == nil {
+				paramErr = err
 			}
-			generateFile(gen, file)
+			return nil
+		},
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		if paramErr != nil {
+			gen.Error(paramErr)
+			return nil
 		}
-		return nil
+		return generate(gen, cfg)
 	})
 }
 
-func generateFile(gen *protogen.Plugin, file *protogen.File) {
+// generate generates the files of a plugin run with the given parameters
+func generate(gen *protogen.Plugin, cfg config) error {
+	if err := cfg.checkServiceFilter(gen.Files); err != nil {
+		return err
+	}
+	if err := checkToolNames(cfg, gen.Files); err != nil {
+		return err
+	}
+	var packages []protogen.GoImportPath
+	packageFiles := make(map[protogen.GoImportPath][]*protogen.File)
+	for _, file := range gen.Files {
+		if !file.Generate {
+			continue
+		}
+		if err := generateFile(gen, file, cfg); err != nil {
+			return err
+		}
+		if _, ok := packageFiles[file.GoImportPath]; !ok {
+			packages = append(packages, file.GoImportPath)
+		}
+		packageFiles[file.GoImportPath] = append(packageFiles[file.GoImportPath], file)
+	}
+	if cfg.ServeFuncs {
+		for _, pkg := range packages {
+			if err := generatePackageFile(gen, packageFiles[pkg], cfg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateFile generates the .mcpserver.go file of a proto file
+func generateFile(gen *protogen.Plugin, file *protogen.File, cfg config) error {
+	services := cfg.services(file)
+	if len(services) == 0 && !cfg.EmptyFiles {
+		return nil
+	}
+	if err := checkSupported(cfg, services); err != nil {
+		return err
+	}
 	filename := file.GeneratedFilenamePrefix + ".mcpserver.go"
 	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	if len(services) > 0 {
+		// Link the google.rpc error details, so that tool errors can
+		// include the ErrorInfo and BadRequest details of a status.
+		g.Import(errdetailsPackage)
+	}
 
-	funcMap := template.FuncMap{
-		"toLower":      strings.ToLower,
-		"fieldType":    getFieldType,
-		"parseFunc":    getParseFunction,
-		"mcpType":      getMcpType,
-		"isRepeated":   isRepeated,
-		"formatOutput": formatOutput,
-		"defaultValue": getDefaultValue,
-		"getBaseType":  getBaseType,
+	fileName := goCamelCase(strings.TrimSuffix(path.Base(file.Desc.Path()), ".proto"))
+	var data = struct {
+		PackageName  string
+		FileName     string
+		HelperPrefix string
+		Services     []*protogen.Service
+		config
+	}{
+		PackageName:  string(file.GoPackageName),
+		FileName:     fileName,
+		HelperPrefix: helperPrefix(fileName),
+		Services:     services,
+		config:       cfg,
 	}
+	if err := executeTemplate(g, cfg, mcpServerTemplate, data); err != nil {
+		return fmt.Errorf("%s: %v", file.Desc.Path(), err)
+	}
+	return nil
+}
 
-	tmpl, err := template.New("mcpserver").Funcs(funcMap).Parse(mcpServerTemplate)
-	if err != nil {
-		g.P("// Error parsing template: ", err)
-		return
+// generatePackageFile generates the file declaring the package-level
+// functions that serve the services of all the files of a Go package, so that
+// packages built from several proto files declare them once
+func generatePackageFile(gen *protogen.Plugin, files []*protogen.File, cfg config) error {
+	var services []*protogen.Service
+	for _, file := range files {
+		services = append(services, cfg.services(file)...)
+	}
+	if len(services) == 0 {
+		return nil
 	}
+	first := files[0]
+	filename := path.Join(path.Dir(first.GeneratedFilenamePrefix), string(first.GoPackageName)+".mcpserver.pkg.go")
+	g := gen.NewGeneratedFile(filename, first.GoImportPath)
 
 	var data = struct {
 		PackageName string
 		Services    []*protogen.Service
-		Methods     map[string][]*protogen.Method
+		config
 	}{
-		PackageName: string(file.GoPackageName),
-		Services:    file.Services,
-		Methods:     make(map[string][]*protogen.Method),
+		PackageName: string(first.GoPackageName),
+		Services:    services,
+		config:      cfg,
+	}
+	if err := executeTemplate(g, cfg, mcpPackageTemplate, data); err != nil {
+		return fmt.Errorf("package %s: %v", first.GoImportPath, err)
+	}
+	return nil
+}
+
+// argChecks is the data of the decodeArgs template, which decodes the
+// arguments of a call, or one item of a client-streaming call, into the
+// request of Method. File is the data of the file template.
+type argChecks struct {
+	File   interface{}
+	Method *protogen.Method
+	Item   bool
+}
+
+// newArgChecks returns the data of the decodeArgs template
+func newArgChecks(file interface{}, method *protogen.Method, item bool) argChecks {
+	return argChecks{File: file, Method: method, Item: item}
+}
+
+// executeTemplate executes one of the code templates into g
+func executeTemplate(g *protogen.GeneratedFile, cfg config, text string, data interface{}) error {
+	funcMap := template.FuncMap{
+		"toLower":        strings.ToLower,
+		"fieldType":      getFieldType,
+		"mcpType":        getMcpType,
+		"isRepeated":     isRepeated,
+		"formatOutput":   formatOutput,
+		"getBaseType":    getBaseType,
+		"methodDesc":     methodDescription,
+		"toolTitle":      toolTitle,
+		"oneofs":         oneofs,
+		"requiredFields": requiredFields,
+		"nestedRequired": nestedRequired,
+		"requiredMsgs":   requiredMessages,
+		"argRenames":     argRenames,
+		"argAliases":     argAliases,
+		"lowerCamel":     lowerCamelCase,
+		"wellKnown":      isWellKnown,
+		"wrapsOutput":    wrapsOutput,
+		"clientStream":   isClientStreaming,
+		"serverStream":   isServerStreaming,
+		"bidiStream":     isBidiStreaming,
+		"sessionTitle":   sessionToolTitle,
+		"mimeFields":     mimeFields,
+		"mapFields":      mapFields,
+		"isBytes":        isBytes,
+		"is64Bit":        is64Bit,
+		"hasBytesParams": hasBytesParams,
+		"hasInt64Params": hasInt64Params,
+		"reaches64Bit":   reaches64Bit,
+		"reachesEnum":    reachesEnum,
+		"hasEnumParams":  hasEnumParams,
+		"argChecks":      newArgChecks,
+		"ident":          g.QualifiedGoIdent,
+		"toolHints": func(method *protogen.Method) []string {
+			return toolHints(g, method)
+		},
+		"fieldOpts": func(field *protogen.Field) string {
+			return fieldPropertyOptions(g, field)
+		},
+		"fieldDesc": func(field *protogen.Field) string {
+			return fieldDescription(cfg, field)
+		},
+		"toolName": func(method *protogen.Method) string {
+			return strconv.Quote(toolName(cfg, method))
+		},
+		"paramName": func(field *protogen.Field) string {
+			return paramName(cfg, field)
+		},
+		"paramNames": func(fields []*protogen.Field) string {
+			return paramNames(cfg, fields)
+		},
+		"schemaOpts": func(method *protogen.Method, field *protogen.Field) string {
+			return fieldSchemaOptions(g, cfg, method, field)
+		},
+		"inputDefs": func(method *protogen.Method) string {
+			return inputDefs(g, cfg, method)
+		},
+		"outputSchema": func(method *protogen.Method) string {
+			return outputSchema(cfg, method)
+		},
+		"itemsOpts": func(method *protogen.Method) string {
+			return itemsSchemaOptions(g, cfg, method)
+		},
+		"sessionTool": func(method *protogen.Method, op string) string {
+			return strconv.Quote(toolName(cfg, method) + "_" + op)
+		},
+		"sessionDesc": func(method *protogen.Method, op string) string {
+			return sessionToolDescription(cfg, method, op)
+		},
+		"mimeType": func(field *protogen.Field) bool {
+			return fieldOptions(field).GetMimeType() != ""
+		},
+		"mimeContent": func(field *protogen.Field) string {
+			return mimeContent(g, field)
+		},
+		"idleTimeout": func() string {
+			return durationLiteral(g, cfg.BidiIdleTimeout)
+		},
+	}
+
+	// Library identifiers are written as {{ mcp "NewTool" }} and the like, so
+	// that protogen imports each package under a name free of conflicts.
+	for name, pkg := range map[string]protogen.GoImportPath{
+		"context":       contextPackage,
+		"errors":        errorsPackage,
+		"fmt":           fmtPackage,
+		"math":          mathPackage,
+		"io":            ioPackage,
+		"strconv":       strconvPackage,
+		"sync":          syncPackage,
+		"time":          timePackage,
+		"json":          jsonPackage,
+		"base64":        base64Package,
+		"strings":       stringsPackage,
+		"mcp":           mcpPackage,
+		"server":        serverPackage,
+		"protojson":     protojsonPackage,
+		"protoreflect":  protoreflectPackage,
+		"protovalidate": protovalidatePackage,
+		"status":        statusPackage,
+	} {
+		pkg := pkg
+		funcMap[name] = func(name string) string {
+			return g.QualifiedGoIdent(pkg.Ident(name))
+		}
 	}
 
-	for _, service := range file.Services {
-		data.Methods[service.GoName] = service.Methods
+	tmpl, err := template.New("mcpserver").Funcs(funcMap).Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing template: %v", err)
 	}
 
 	var builder strings.Builder
 	if err := tmpl.Execute(&builder, data); err != nil {
-		g.P("// Error executing template: ", err)
-		return
+		return fmt.Errorf("executing template: %v", err)
 	}
 
 	g.P(builder.String())
+	return nil
+}
+
+// helperPrefix returns the prefix of the unexported helpers generated for a
+// file, such as "userServiceProto", which keeps the helpers of the files of a
+// package apart. Names that would start with a digit are prefixed with "file".
+func helperPrefix(fileName string) string {
+	prefix := lowerCamelCase(fileName) + "Proto"
+	if unicode.IsDigit([]rune(prefix)[0]) {
+		prefix = "file" + prefix
+	}
+	return prefix
+}
+
+// goCamelCase converts a file base name such as "user_service" to a Go
+// identifier such as "UserService"
+func goCamelCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// commentText flattens leading and trailing proto comments into plain text,
+// dropping the comment indentation and surrounding blank lines
+func commentText(comments ...protogen.Comments) string {
+	var parts []string
+	for _, c := range comments {
+		lines := strings.Split(strings.TrimSpace(string(c)), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(line)
+		}
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// methodDescription returns the tool description for a method as a quoted Go
+// string literal. The (mcpserver.method) description is used first, then the
+// method comments, falling back to the comments of the input message.
+func methodDescription(method *protogen.Method) string {
+	desc := methodOptions(method).GetDescription()
+	if desc == "" {
+		desc = commentText(method.Comments.Leading, method.Comments.Trailing)
+	}
+	if desc == "" {
+		desc = commentText(method.Input.Comments.Leading, method.Input.Comments.Trailing)
+	}
+	if desc == "" {
+		desc = method.GoName + " description"
+	}
+	return strconv.Quote(desc)
+}
+
+// sessionToolDescription returns the description of one of the session tools
+// of a bidirectional streaming method, or of their "session" parameter, as a
+// quoted Go string literal
+func sessionToolDescription(cfg config, method *protogen.Method, op string) string {
+	name := toolName(cfg, method)
+	var desc string
+	switch op {
+	case "open":
+		desc, _ = strconv.Unquote(methodDescription(method))
+		desc += fmt.Sprintf("\n\nOpens a session and returns its id. Send messages with %s_send, read the responses with %s_receive, "+
+			"and end the session with %s_close. Sessions are canceled after %v without a call.", name, name, name, cfg.BidiIdleTimeout)
+	case "send":
+		desc = fmt.Sprintf("Sends a message to a session opened with %s_open.", name)
+	case "receive":
+		desc = fmt.Sprintf("Returns the messages of a session opened with %s_open since the previous call, and whether the session has ended.", name)
+	case "close":
+		desc = fmt.Sprintf("Ends the input of a session opened with %s_open, waits for it to finish, and returns its last messages.", name)
+	case "session":
+		desc = fmt.Sprintf("Session id returned by %s_open", name)
+	}
+	return strconv.Quote(desc)
+}
+
+// durationLiteral returns a Go expression for a duration in the largest unit
+// that divides it
+func durationLiteral(g *protogen.GeneratedFile, d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "Hour"},
+		{time.Minute, "Minute"},
+		{time.Second, "Second"},
+		{time.Millisecond, "Millisecond"},
+		{time.Microsecond, "Microsecond"},
+	} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.d, g.QualifiedGoIdent(timePackage.Ident(unit.name)))
+		}
+	}
+	return fmt.Sprintf("%d * %s", d, g.QualifiedGoIdent(timePackage.Ident("Nanosecond")))
+}
+
+// fieldDescription returns the parameter description for a field as a quoted Go
+// string literal.
+func fieldDescription(cfg config, field *protogen.Field) string {
+	desc := fieldDescriptionText(field)
+	if desc == "" {
+		desc = "Parameter " + paramName(cfg, field)
+	}
+	// Top-level oneof constraints can't be expressed in the input schema, as
+	// many clients reject oneOf at the root of a tool schema, so they are
+	// spelled out for the agent instead.
+	if field.Oneof != nil && !field.Oneof.Desc.IsSynthetic() {
+		desc += "\n\nOnly one of " + paramNames(cfg, field.Oneof.Fields) + " may be set."
+	}
+	return strconv.Quote(desc)
+}
+
+// fieldDescriptionText returns the description of a field. The
+// (mcpserver.field) description is used first, then the field comments,
+// falling back to the comments of the field's message or enum type.
+func fieldDescriptionText(field *protogen.Field) string {
+	desc := fieldOptions(field).GetDescription()
+	if desc == "" {
+		desc = commentText(field.Comments.Leading, field.Comments.Trailing)
+	}
+	if desc == "" && field.Message != nil {
+		if schema := wktSchema(field.Message); schema != nil {
+			desc, _ = schema["description"].(string)
+		} else {
+			desc = commentText(field.Message.Comments.Leading, field.Message.Comments.Trailing)
+		}
+	}
+	if desc == "" && field.Enum != nil {
+		desc = commentText(field.Enum.Comments.Leading, field.Enum.Comments.Trailing)
+	}
+	if field.Enum != nil {
+		var values []string
+		for _, value := range field.Enum.Values {
+			if text := commentText(value.Comments.Leading, value.Comments.Trailing); text != "" {
+				values = append(values, "- "+string(value.Desc.Name())+": "+strings.ReplaceAll(text, "\n", " "))
+			}
+		}
+		if len(values) > 0 {
+			desc = strings.TrimSpace(desc + "\n\nValues:\n" + strings.Join(values, "\n"))
+		}
+	}
+	return desc
+}
+
+// paramName returns the name of the tool parameter generated for a field
+func paramName(cfg config, field *protogen.Field) string {
+	switch cfg.FieldNaming {
+	case "json":
+		return field.Desc.JSONName()
+	case "proto":
+		return string(field.Desc.Name())
+	default:
+		return field.GoName
+	}
+}
+
+// paramNames returns the parameter names of fields as a comma-separated list
+func paramNames(cfg config, fields []*protogen.Field) string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = paramName(cfg, field)
+	}
+	return strings.Join(names, ", ")
+}
+
+// oneofs returns the oneofs of a message, excluding the synthetic oneofs of
+// proto3 optional fields
+func oneofs(msg *protogen.Message) []*protogen.Oneof {
+	var result []*protogen.Oneof
+	for _, oneof := range msg.Oneofs {
+		if !oneof.Desc.IsSynthetic() {
+			result = append(result, oneof)
+		}
+	}
+	return result
+}
+
+// requiredFields returns the fields of a message that must be set by the caller
+func requiredFields(msg *protogen.Message) []*protogen.Field {
+	var fields []*protogen.Field
+	for _, field := range msg.Fields {
+		if isRequired(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// nestedRequired returns the messages reached through the fields of msg, at
+// any depth, that have required fields
+func nestedRequired(msg *protogen.Message) []*protogen.Message {
+	var msgs []*protogen.Message
+	seen := make(map[*protogen.Message]bool)
+	var walk func(msg *protogen.Message)
+	walk = func(msg *protogen.Message) {
+		for _, field := range msg.Fields {
+			if field.Desc.IsMap() {
+				field = field.Message.Fields[1]
+			}
+			m := field.Message
+			if m == nil || seen[m] || m.Desc.ParentFile().Package() == "google.protobuf" {
+				continue
+			}
+			seen[m] = true
+			if len(requiredFields(m)) > 0 {
+				msgs = append(msgs, m)
+			}
+			walk(m)
+		}
+	}
+	walk(msg)
+	return msgs
+}
+
+// requiredMessages returns the messages nested in the requests of the
+// services that have required fields, which the generated handlers check
+// are set at any depth
+func requiredMessages(services []*protogen.Service) []*protogen.Message {
+	var msgs []*protogen.Message
+	seen := make(map[*protogen.Message]bool)
+	for _, service := range services {
+		for _, method := range service.Methods {
+			for _, msg := range nestedRequired(method.Input) {
+				if !seen[msg] {
+					seen[msg] = true
+					msgs = append(msgs, msg)
+				}
+			}
+		}
+	}
+	return msgs
+}
+
+// argAliases returns the spellings accepted for a field's parameter besides
+// its proto JSON name: the proto field name and the Go field name, which the
+// parameters were named after before field_naming defaulted to json. Aliases
+// that are the JSON or proto name of another field are left out.
+func argAliases(field *protogen.Field) []string {
+	taken := make(map[string]bool)
+	for _, f := range field.Parent.Fields {
+		taken[f.Desc.JSONName()] = true
+		taken[string(f.Desc.Name())] = true
+	}
+	var aliases []string
+	for _, alias := range []string{string(field.Desc.Name()), field.GoName} {
+		if alias == field.Desc.JSONName() || (taken[alias] && alias != string(field.Desc.Name())) {
+			continue
+		}
+		if len(aliases) == 0 || aliases[0] != alias {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// argRenames returns the input fields of a method accepted under other names
+// than their proto JSON name
+func argRenames(method *protogen.Method) []*protogen.Field {
+	var fields []*protogen.Field
+	for _, field := range method.Input.Fields {
+		if len(argAliases(field)) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
 }
 
 // isRepeated checks if a field is a repeated field (array/slice)
@@ -84,8 +590,168 @@ func isRepeated(field *protogen.Field) bool {
 	return field.Desc.Cardinality() == protoreflect.Repeated && !field.Desc.IsMap()
 }
 
-// getFieldType returns the Go type of a protobuf field
+// isClientStreaming reports whether only the requests of a method are
+// streamed
+func isClientStreaming(method *protogen.Method) bool {
+	return method.Desc.IsStreamingClient() && !method.Desc.IsStreamingServer()
+}
+
+// isServerStreaming reports whether only the responses of a method are
+// streamed
+func isServerStreaming(method *protogen.Method) bool {
+	return method.Desc.IsStreamingServer() && !method.Desc.IsStreamingClient()
+}
+
+// isBidiStreaming reports whether both the requests and responses of a method
+// are streamed
+func isBidiStreaming(method *protogen.Method) bool {
+	return method.Desc.IsStreamingClient() && method.Desc.IsStreamingServer()
+}
+
+// wrapsOutput reports whether a response message is a well-known type whose
+// JSON form isn't an object, such as the string of a Timestamp, so that its
+// structured content is wrapped in {"value": ...}
+func wrapsOutput(msg *protogen.Message) bool {
+	schema := wktSchema(msg)
+	return schema != nil && schema["type"] != "object"
+}
+
+// mapFields returns the map fields of a message
+func mapFields(msg *protogen.Message) []*protogen.Field {
+	var fields []*protogen.Field
+	for _, field := range msg.Fields {
+		if field.Desc.IsMap() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// isBytes reports whether a field holds bytes, or a list of them
+func isBytes(field *protogen.Field) bool {
+	return field.Desc.Kind() == protoreflect.BytesKind
+}
+
+// is64Bit reports whether a field holds 64-bit integers, including the
+// Int64Value and UInt64Value wrappers, which protojson writes as strings so
+// that values beyond 2^53 survive JSON
+func is64Bit(field *protogen.Field) bool {
+	switch field.Desc.Kind() {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	case protoreflect.MessageKind:
+		name := field.Message.Desc.FullName()
+		return name == "google.protobuf.Int64Value" || name == "google.protobuf.UInt64Value"
+	default:
+		return false
+	}
+}
+
+// reaches64Bit reports whether a message is, or reaches through its fields,
+// a 64-bit integer, which the generated handlers check wasn't rounded
+func reaches64Bit(msg *protogen.Message) bool {
+	return reaches64BitFrom(msg, make(map[*protogen.Message]bool))
+}
+
+func reaches64BitFrom(msg *protogen.Message, seen map[*protogen.Message]bool) bool {
+	switch msg.Desc.FullName() {
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return true
+	}
+	if seen[msg] || msg.Desc.ParentFile().Package() == "google.protobuf" {
+		return false
+	}
+	seen[msg] = true
+	for _, field := range msg.Fields {
+		if field.Desc.IsMap() {
+			field = field.Message.Fields[1]
+		}
+		if is64Bit(field) || field.Message != nil && reaches64BitFrom(field.Message, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// reachesEnum reports whether a message reaches an enum through its fields,
+// at any depth, which the generated handlers check is a known value
+func reachesEnum(msg *protogen.Message) bool {
+	return reachesEnumFrom(msg, make(map[*protogen.Message]bool))
+}
+
+func reachesEnumFrom(msg *protogen.Message, seen map[*protogen.Message]bool) bool {
+	if seen[msg] || msg.Desc.ParentFile().Package() == "google.protobuf" {
+		return false
+	}
+	seen[msg] = true
+	for _, field := range msg.Fields {
+		if field.Desc.IsMap() {
+			field = field.Message.Fields[1]
+		}
+		if field.Enum != nil || field.Message != nil && reachesEnumFrom(field.Message, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEnumParams reports whether a tool of the services takes enums, at any
+// depth
+func hasEnumParams(services []*protogen.Service) bool {
+	for _, service := range services {
+		for _, method := range service.Methods {
+			if reachesEnum(method.Input) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasInt64Params reports whether a tool of the services takes 64-bit
+// integers, at any depth
+func hasInt64Params(services []*protogen.Service) bool {
+	for _, service := range services {
+		for _, method := range service.Methods {
+			if reaches64Bit(method.Input) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasBytesParams reports whether a tool of the services takes bytes
+// parameters, which the generated handlers check for valid base64
+func hasBytesParams(services []*protogen.Service) bool {
+	for _, service := range services {
+		for _, method := range service.Methods {
+			if isWellKnown(method.Input) {
+				continue
+			}
+			for _, field := range method.Input.Fields {
+				if isBytes(field) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isWellKnown reports whether a message has its own JSON form, such as the
+// string of a google.protobuf.Timestamp, instead of an object of its fields
+func isWellKnown(msg *protogen.Message) bool {
+	return wktSchema(msg) != nil
+}
+
+// getFieldType returns the Go type of a scalar protobuf field, or of a list or
+// map of them
 func getFieldType(field *protogen.Field) string {
+	if field.Desc.IsMap() {
+		return "map[" + getBaseType(field.Message.Fields[0]) + "]" + getBaseType(field.Message.Fields[1])
+	}
 	if isRepeated(field) {
 		baseType := getBaseType(field)
 		return "[]" + baseType
@@ -94,7 +760,10 @@ func getFieldType(field *protogen.Field) string {
 	return getBaseType(field)
 }
 
-// getBaseType returns the base Go type of a protobuf field
+// getBaseType returns the base Go type of a scalar protobuf field. Enums and
+// messages have no predeclared Go type, and their identifiers must be
+// qualified through the generated file, so the templates tell them apart with
+// field.Enum and field.Message instead.
 func getBaseType(field *protogen.Field) string {
 	switch field.Desc.Kind() {
 	case protoreflect.BoolKind:
@@ -115,10 +784,8 @@ func getBaseType(field *protogen.Field) string {
 		return "string"
 	case protoreflect.BytesKind:
 		return "[]byte"
-	case protoreflect.MessageKind:
-		return "map[string]interface{}"
 	default:
-		return "string" // default to string for unsupported types
+		return "" // enums and messages, see field.Enum and field.Message
 	}
 }
 
@@ -142,6 +809,9 @@ func getMcpType(field *protogen.Field) string {
 	case protoreflect.StringKind:
 		return "WithString"
 	case protoreflect.MessageKind:
+		if schema := wktSchema(field.Message); schema != nil {
+			return wktMcpType(schema)
+		}
 		return "WithObject"
 	case protoreflect.BytesKind:
 		return "WithString" // Bytes represented as base64 string
@@ -150,58 +820,24 @@ func getMcpType(field *protogen.Field) string {
 	}
 }
 
-// getParseFunction returns the appropriate parse function for a protobuf field
-func getParseFunction(field *protogen.Field) string {
-	// For repeated fields, we would need custom handling in the template
-	if isRepeated(field) {
-		// The template will need to handle array parsing specially
-		return "ParseArray"
-	}
-
-	switch field.Desc.Kind() {
-	case protoreflect.BoolKind:
-		return "ParseBoolean"
-	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
-		return "ParseInt32"
-	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
-		return "ParseUInt32"
-	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
-		return "ParseInt64"
-	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
-		return "ParseUInt64"
-	case protoreflect.FloatKind:
-		return "ParseFloat32"
-	case protoreflect.DoubleKind:
-		return "ParseFloat64"
-	case protoreflect.StringKind:
-		return "ParseString"
-	case protoreflect.BytesKind:
-		return "ParseString" // Parse as string, convert to []byte later
-	case protoreflect.MessageKind:
-		return "ParseStringMap"
-	default:
-		return "ParseString" // Default to string for unsupported types
-	}
-}
-
-// getDefaultValue returns the default value for a field type
-func getDefaultValue(field *protogen.Field) string {
-	if isRepeated(field) {
-		return "nil"
+// wktMcpType returns the MCP property type matching the JSON type of a
+// well-known type schema
+func wktMcpType(schema map[string]interface{}) string {
+	typ := schema["type"]
+	if types, ok := typ.([]interface{}); ok {
+		typ = types[0]
 	}
-
-	switch field.Desc.Kind() {
-	case protoreflect.BoolKind:
-		return "false"
-	case protoreflect.StringKind:
-		return "\"\""
-	case protoreflect.BytesKind:
-		return "nil"
-	case protoreflect.MessageKind:
-		return "nil"
+	switch typ {
+	case "string":
+		return "WithString"
+	case "integer", "number":
+		return "WithNumber"
+	case "boolean":
+		return "WithBoolean"
+	case "array":
+		return "WithArray"
 	default:
-		// For all numeric types
-		return "0"
+		return "WithObject"
 	}
 }
 
@@ -227,142 +863,1059 @@ func formatOutput(field *protogen.Field) string {
 	}
 }
 
+// mcpServerTemplate declares the interfaces and tool registration of the
+// services of a proto file
 const mcpServerTemplate = `
 // Code generated by protoc-gen-mcpserver. DO NOT EDIT.
 package {{ .PackageName }}
 
-import (
-	"context"
-	"fmt"
-
-	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
-)
-
 {{- range $service := .Services }}
-type {{ $service.GoName }}McpServer interface {
+type {{ $service.GoName }}{{ $.InterfaceSuffix }} interface {
 	{{- range $method := $service.Methods }}
-	{{ $method.GoName }}(ctx context.Context, req *{{ $method.Input.GoIdent.GoName }}) (*{{ $method.Output.GoIdent.GoName }}, error)
+	{{- if bidiStream $method }}
+	{{ $method.GoName }}(ctx {{ context "Context" }}, stream {{ $service.GoName }}{{ $.InterfaceSuffix }}_{{ $method.GoName }}Stream) error
+	{{- else if serverStream $method }}
+	{{ $method.GoName }}(ctx {{ context "Context" }}, req *{{ ident $method.Input.GoIdent }}, send func(*{{ ident $method.Output.GoIdent }}) error) error
+	{{- else if clientStream $method }}
+	{{ $method.GoName }}(ctx {{ context "Context" }}, stream {{ $service.GoName }}{{ $.InterfaceSuffix }}_{{ $method.GoName }}Stream) (*{{ ident $method.Output.GoIdent }}, error)
+	{{- else }}
+	{{ $method.GoName }}(ctx {{ context "Context" }}, req *{{ ident $method.Input.GoIdent }}) (*{{ ident $method.Output.GoIdent }}, error)
+	{{- end }}
 	{{- end }}
 }
+{{- range $method := $service.Methods }}
+{{- if clientStream $method }}
+{{ $stream := printf "%s%s_%sStream" $service.GoName $.InterfaceSuffix $method.GoName }}
+// {{ $stream }} passes the items of the {{ $method.GoName }} tool call to
+// {{ $service.GoName }}{{ $.InterfaceSuffix }}.{{ $method.GoName }}
+type {{ $stream }} interface {
+	// Recv returns the next item, or io.EOF after the last one
+	Recv() (*{{ ident $method.Input.GoIdent }}, error)
+}
 
-func Register{{ $service.GoName }}McpServer(s *server.MCPServer, srv {{ $service.GoName }}McpServer) {
-	{{- range $method := $service.Methods }}
-	s.AddTool(
-		mcp.NewTool(
-			"{{ $method.GoName }}",
-			mcp.WithDescription("{{ $method.GoName }} description"),
-			mcp.WithToolAnnotation(mcp.ToolAnnotation{
-				Title: "{{ $method.GoName }}",
-			}),
-			{{- range $field := $method.Input.Fields }}
-			mcp.{{ mcpType $field }}("{{ $field.GoName }}", mcp.Description("Parameter {{ $field.GoName }}")),
-			{{- end }}
-		),
-		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			req := &{{ $method.Input.GoIdent.GoName }}{}
-			
-			{{- range $field := $method.Input.Fields }}
-			{{- if isRepeated $field }}
-			// Handle repeated field {{ $field.GoName }}
-			if arr, ok := request.Params.Arguments["{{ $field.GoName }}"]; ok && arr != nil {
-				if arrValue, ok := arr.([]interface{}); ok {
-					for _, v := range arrValue {
-						{{- if eq (getBaseType $field) "string" }}
-						if strVal, ok := v.(string); ok {
-							req.{{ $field.GoName }} = append(req.{{ $field.GoName }}, strVal)
-						}
-						{{- else if eq (getBaseType $field) "bool" }}
-						if boolVal, ok := v.(bool); ok {
-							req.{{ $field.GoName }} = append(req.{{ $field.GoName }}, boolVal)
-						}
-						{{- else if or (eq (getBaseType $field) "int32") (eq (getBaseType $field) "int64") }}
-						if numVal, ok := v.(float64); ok { // JSON numbers are float64
-							req.{{ $field.GoName }} = append(req.{{ $field.GoName }}, {{ getBaseType $field }}(numVal))
-						}
-						{{- else if or (eq (getBaseType $field) "uint32") (eq (getBaseType $field) "uint64") }}
-						if numVal, ok := v.(float64); ok && numVal >= 0 {
-							req.{{ $field.GoName }} = append(req.{{ $field.GoName }}, {{ getBaseType $field }}(numVal))
-						}
-						{{- else if or (eq (getBaseType $field) "float32") (eq (getBaseType $field) "float64") }}
-						if numVal, ok := v.(float64); ok {
-							req.{{ $field.GoName }} = append(req.{{ $field.GoName }}, {{ getBaseType $field }}(numVal))
-						}
-						{{- else }}
-						// Unsupported array element type
-						{{- end }}
-					}
-				}
-			}
-			{{- else }}
-			req.{{ $field.GoName }} = mcp.{{ parseFunc $field }}(request, "{{ $field.GoName }}", {{ defaultValue $field }})
-			{{- end }}
-			{{- end }}
-			
-			res, err := srv.{{ $method.GoName }}(ctx, req)
-			if err != nil {
-				return nil, err
+type {{ lowerCamel $stream }} struct {
+	items []*{{ ident $method.Input.GoIdent }}
+}
+
+func (s *{{ lowerCamel $stream }}) Recv() (*{{ ident $method.Input.GoIdent }}, error) {
+	if len(s.items) == 0 {
+		return nil, {{ io "EOF" }}
+	}
+	item := s.items[0]
+	s.items = s.items[1:]
+	return item, nil
+}
+
+{{- else if bidiStream $method }}
+{{ $stream := printf "%s%s_%sStream" $service.GoName $.InterfaceSuffix $method.GoName }}
+{{- $impl := lowerCamel $stream }}
+{{- $sessions := lowerCamel (printf "%s%s_%sSessions" $service.GoName $.InterfaceSuffix $method.GoName) }}
+{{- $in := ident $method.Input.GoIdent }}
+{{- $out := ident $method.Output.GoIdent }}
+// {{ $stream }} connects {{ $service.GoName }}{{ $.InterfaceSuffix }}.{{ $method.GoName }} to the
+// session tools of a {{ $method.GoName }} session
+type {{ $stream }} interface {
+	// Recv returns the next message sent with the _send tool, or io.EOF once
+	// the session is closed
+	Recv() (*{{ $in }}, error)
+	// Send queues a message for the _receive tool
+	Send(*{{ $out }}) error
+}
+
+// {{ $impl }} is the stream of one {{ $method.GoName }} session
+type {{ $impl }} struct {
+	ctx      {{ context "Context" }}
+	cancel   {{ context "CancelFunc" }}
+	in       chan *{{ $in }}
+	eof      chan struct{}
+	closeIn  {{ sync "Once" }}
+	finished chan struct{}
+	err      error
+	idle     *{{ time "Timer" }}
+
+	mu    {{ sync "Mutex" }}
+	out   []{{ json "RawMessage" }}
+	ready chan struct{}
+}
+
+func (s *{{ $impl }}) Recv() (*{{ $in }}, error) {
+	select {
+	case req := <-s.in:
+		return req, nil
+	case <-s.eof:
+		return nil, {{ io "EOF" }}
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+func (s *{{ $impl }}) Send(res *{{ $out }}) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	out, err := {{ protojson "MarshalOptions" }}{EmitDefaultValues: true}.Marshal(res)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out = append(s.out, out)
+	close(s.ready)
+	s.ready = make(chan struct{})
+	return nil
+}
+
+// send passes a message sent with the _send tool to Recv
+func (s *{{ $impl }}) send(ctx {{ context "Context" }}, req *{{ $in }}) error {
+	select {
+	case s.in <- req:
+		return nil
+	case <-s.eof:
+		return {{ errors "New" }}("session is closed")
+	case <-s.finished:
+		return {{ errors "New" }}("session has ended")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closeSend makes Recv return io.EOF once the messages sent so far are read
+func (s *{{ $impl }}) closeSend() {
+	s.closeIn.Do(func() { close(s.eof) })
+}
+
+// finish records the error the method returned with
+func (s *{{ $impl }}) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	close(s.finished)
+	close(s.ready)
+	s.ready = make(chan struct{})
+}
+
+// result returns the messages queued since the previous call as a tool
+// result, waiting up to wait for one when there are none, and whether the
+// method has returned
+func (s *{{ $impl }}) result(ctx {{ context "Context" }}, wait {{ time "Duration" }}) (*{{ mcp "CallToolResult" }}, bool) {
+	s.mu.Lock()
+	select {
+	case <-s.finished:
+		wait = 0
+	default:
+	}
+	if len(s.out) == 0 && wait > 0 {
+		ready := s.ready
+		s.mu.Unlock()
+		timer := {{ time "NewTimer" }}(wait)
+		select {
+		case <-ready:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+		timer.Stop()
+		s.mu.Lock()
+	}
+	messages := append([]{{ json "RawMessage" }}{}, s.out...)
+	s.out = nil
+	s.mu.Unlock()
+
+	res := map[string]interface{}{"messages": messages, "done": false}
+	done := false
+	select {
+	case <-s.finished:
+		done = true
+		res["done"] = true
+		if s.err != nil {
+			res["error"] = {{ $.HelperPrefix }}Status(s.err)
+		}
+	default:
+	}
+	out, _ := {{ json "Marshal" }}(res) // res only holds JSON values
+	{{- if eq $.Output "structured" }}
+	result := {{ mcp "NewToolResultStructured" }}({{ json "RawMessage" }}(out), string(out))
+	{{- else }}
+	result := {{ mcp "NewToolResultText" }}(string(out))
+	{{- end }}
+	result.IsError = done && s.err != nil
+	return result, done
+}
+
+// {{ $sessions }} holds the open {{ $method.GoName }} sessions of a server
+type {{ $sessions }} struct {
+	srv     {{ $service.GoName }}{{ $.InterfaceSuffix }}
+	mu      {{ sync "Mutex" }}
+	next    int
+	streams map[string]*{{ $impl }}
+}
+
+// key returns the registry key of a session id, scoped to the MCP session of
+// ctx so that clients can't reach each other's sessions
+func (r *{{ $sessions }}) key(ctx {{ context "Context" }}, id string) string {
+	if session := {{ server "ClientSessionFromContext" }}(ctx); session != nil {
+		return session.SessionID() + "/" + id
+	}
+	return "/" + id
+}
+
+// open starts {{ $method.GoName }} in a new session and returns the session id
+func (r *{{ $sessions }}) open(ctx {{ context "Context" }}) string {
+	streamCtx, cancel := {{ context "WithCancel" }}({{ context "Background" }}())
+	s := &{{ $impl }}{
+		ctx:      streamCtx,
+		cancel:   cancel,
+		in:       make(chan *{{ $in }}),
+		eof:      make(chan struct{}),
+		finished: make(chan struct{}),
+		ready:    make(chan struct{}),
+	}
+	r.mu.Lock()
+	r.next++
+	id := {{ strconv "Itoa" }}(r.next)
+	key := r.key(ctx, id)
+	s.idle = {{ time "AfterFunc" }}({{ idleTimeout }}, func() { r.remove(key) })
+	r.streams[key] = s
+	r.mu.Unlock()
+	go func() {
+		s.finish(r.srv.{{ $method.GoName }}(streamCtx, s))
+	}()
+	return id
+}
+
+// get returns the session with the given id, or nil when there is none, and
+// restarts its idle timeout
+func (r *{{ $sessions }}) get(ctx {{ context "Context" }}, id string) *{{ $impl }} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.streams[r.key(ctx, id)]
+	if s != nil {
+		s.idle.Reset({{ idleTimeout }})
+	}
+	return s
+}
+
+// remove drops a session from the registry and cancels its context
+func (r *{{ $sessions }}) remove(key string) {
+	r.mu.Lock()
+	s := r.streams[key]
+	delete(r.streams, key)
+	r.mu.Unlock()
+	if s != nil {
+		s.idle.Stop()
+		s.cancel()
+	}
+}
+{{- end }}
+{{- end }}
+
+func Register{{ $service.GoName }}{{ $.InterfaceSuffix }}(s *{{ server "MCPServer" }}, srv {{ $service.GoName }}{{ $.InterfaceSuffix }}) {
+	{{- range $method := $service.Methods }}
+	{{- if bidiStream $method }}
+	{{ lowerCamel $method.GoName }}Sessions := &{{ lowerCamel (printf "%s%s_%sSessions" $service.GoName $.InterfaceSuffix $method.GoName) }}{
+		srv:     srv,
+		streams: make(map[string]*{{ lowerCamel (printf "%s%s_%sStream" $service.GoName $.InterfaceSuffix $method.GoName) }}),
+	}
+	{{- end }}
+	{{- end }}
+	{{- range $method := $service.Methods }}
+	{{- $sessions := printf "%sSessions" (lowerCamel $method.GoName) }}
+	{{- if bidiStream $method }}
+	s.AddTool(
+		{{ mcp "NewTool" }}(
+			{{ sessionTool $method "open" }},
+			{{ mcp "WithDescription" }}({{ sessionDesc $method "open" }}),
+			{{ mcp "WithToolAnnotation" }}({{ mcp "ToolAnnotation" }}{
+				Title: {{ sessionTitle $method "open" }},
+				{{- range $hint := toolHints $method }}
+				{{ $hint }},
+				{{- end }}
+			}),
+			{{- if eq $.Output "structured" }}
+			{{ mcp "WithRawOutputSchema" }}({{ json "RawMessage" }}(` + "`" + `{"type":"object","properties":{"session":{"type":"string"}},"required":["session"]}` + "`" + `)),
+			{{- end }}
+		),
+		func(ctx {{ context "Context" }}, request {{ mcp "CallToolRequest" }}) (*{{ mcp "CallToolResult" }}, error) {
+			out, _ := {{ json "Marshal" }}(map[string]interface{}{"session": {{ $sessions }}.open(ctx)})
+			{{- if eq $.Output "structured" }}
+			return {{ mcp "NewToolResultStructured" }}({{ json "RawMessage" }}(out), string(out)), nil
+			{{- else }}
+			return {{ mcp "NewToolResultText" }}(string(out)), nil
+			{{- end }}
+		},
+	)
+	{{- end }}
+	s.AddTool(
+		{{ mcp "NewTool" }}(
+			{{- if bidiStream $method }}
+			{{ sessionTool $method "send" }},
+			{{ mcp "WithDescription" }}({{ sessionDesc $method "send" }}),
+			{{- else }}
+			{{ toolName $method }},
+			{{ mcp "WithDescription" }}({{ methodDesc $method }}),
+			{{- end }}
+			{{ mcp "WithToolAnnotation" }}({{ mcp "ToolAnnotation" }}{
+				Title: {{ if bidiStream $method }}{{ sessionTitle $method "send" }}{{ else }}{{ toolTitle $method }}{{ end }},
+				{{- range $hint := toolHints $method }}
+				{{ $hint }},
+				{{- end }}
+			}),
+			{{- if bidiStream $method }}
+			{{ mcp "WithString" }}("session", {{ mcp "Required" }}(), {{ mcp "Description" }}({{ sessionDesc $method "session" }})),
+			{{- end }}
+			{{- if clientStream $method }}
+			{{ mcp "WithArray" }}("items", {{ mcp "Description" }}("{{ $method.Input.GoIdent.GoName }} messages to stream to the method, in order"){{ itemsOpts $method }}),
+			{{- end }}
+			{{- range $field := $method.Input.Fields }}
+			{{- if not (clientStream $method) }}
+			{{ mcp (mcpType $field) }}("{{ paramName $field }}", {{ mcp "Description" }}({{ fieldDesc $field }}){{ fieldOpts $field }}{{ schemaOpts $method $field }}),
+			{{- end }}
+			{{- end }}
+			{{- with inputDefs $method }}
+			{{ . }},
+			{{- end }}
+			{{- if and (eq $.Output "structured") (not (bidiStream $method)) }}
+			{{ mcp "WithRawOutputSchema" }}({{ json "RawMessage" }}({{ outputSchema $method }})),
+			{{- end }}
+		),
+		func(ctx {{ context "Context" }}, request {{ mcp "CallToolRequest" }}) (*{{ mcp "CallToolResult" }}, error) {
+			{{- if bidiStream $method }}
+			id, _ := request.GetArguments()["session"].(string)
+			{{- end }}
+			{{- if clientStream $method }}
+			values, ok := request.GetArguments()["items"].([]interface{})
+			if !ok && request.GetArguments()["items"] != nil {
+				return {{ mcp "NewToolResultError" }}("invalid arguments: items must be an array"), nil
 			}
-			
-			result := &mcp.CallToolResult{
-				Result:  mcp.Result{},
-				Content: []mcp.Content{},
+			items := make([]*{{ ident $method.Input.GoIdent }}, len(values))
+			for i, value := range values {
+				{{- if wellKnown $method.Input }}
+				{{- if reaches64Bit $method.Input }}
+				if _, ok := {{ $.HelperPrefix }}InexactInt((&{{ ident $method.Input.GoIdent }}{}).ProtoReflect().Descriptor(), value); ok {
+					return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("invalid items[%d]: integers beyond 2^53 must be passed as decimal strings", i)), nil
+				}
+				{{- end }}
+				data, err := {{ json "Marshal" }}(value)
+				if err != nil {
+					return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("invalid items[%d]: %v", i, err)), nil
+				}
+				req := &{{ ident $method.Input.GoIdent }}{}
+				if err := ({{ protojson "UnmarshalOptions" }}{DiscardUnknown: {{ $.DiscardUnknown }}}).Unmarshal(data, req); err != nil {
+					return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("invalid items[%d]: %v", i, err)), nil
+				}
+				{{- else }}
+				in, ok := value.(map[string]interface{})
+				if !ok {
+					return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("invalid items[%d]: must be an object", i)), nil
+				}
+				{{- template "decodeArgs" (argChecks $ $method true) }}
+				{{- end }}
+				items[i] = req
+			}
+			{{- else }}
+			in := request.GetArguments()
+			{{- template "decodeArgs" (argChecks $ $method false) }}
+			{{- end }}
+			{{ if bidiStream $method }}
+			stream := {{ $sessions }}.get(ctx, id)
+			if stream == nil {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("unknown session %q", id)), nil
+			}
+			if err := stream.send(ctx, req); err != nil {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("session %s: %v", id, err)), nil
+			}
+			return {{ mcp "NewToolResultText" }}("sent"), nil
+			{{- else if serverStream $method }}
+			// Forward each streamed message as a progress notification when the
+			// client asked for progress, and return them all at the end.
+			var progressToken {{ mcp "ProgressToken" }}
+			if request.Params.Meta != nil {
+				progressToken = request.Params.Meta.ProgressToken
+			}
+			messages := []{{ json "RawMessage" }}{}
+			err = srv.{{ $method.GoName }}(ctx, req, func(res *{{ ident $method.Output.GoIdent }}) error {
+				out, err := {{ protojson "MarshalOptions" }}{EmitDefaultValues: true}.Marshal(res)
+				if err != nil {
+					return err
+				}
+				messages = append(messages, out)
+				if s := {{ server "ServerFromContext" }}(ctx); s != nil && progressToken != nil {
+					// Notifications are best effort; the messages are part of
+					// the result either way.
+					_ = s.SendNotificationToClient(ctx, "notifications/progress", map[string]interface{}{
+						"progressToken": progressToken,
+						"progress":      len(messages),
+						"message":       string(out),
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return {{ $.HelperPrefix }}ToolError(ctx, err)
+			}
+			{{- if eq $.Output "text" }}
+			result := &{{ mcp "CallToolResult" }}{Content: []{{ mcp "Content" }}{}}
+			for _, out := range messages {
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}(string(out)))
+			}
+			return result, nil
+			{{- else }}
+			out, err := {{ json "Marshal" }}(map[string]interface{}{"messages": messages})
+			if err != nil {
+				return nil, err
+			}
+			{{- if eq $.Output "structured" }}
+			return {{ mcp "NewToolResultStructured" }}({{ json "RawMessage" }}(out), string(out)), nil
+			{{- else }}
+			return {{ mcp "NewToolResultText" }}(string(out)), nil
+			{{- end }}
+			{{- end }}
+			{{- else }}
+			{{- if clientStream $method }}
+			{{ if and (eq $.Output "text") (not $method.Output.Fields) }}_{{ else }}res{{ end }}, err := srv.{{ $method.GoName }}(ctx, &{{ lowerCamel (printf "%s%s_%sStream" $service.GoName $.InterfaceSuffix $method.GoName) }}{items: items})
+			{{- else }}
+			{{ if and (eq $.Output "text") (not $method.Output.Fields) }}_, err = {{ else }}res, err := {{ end }}srv.{{ $method.GoName }}(ctx, req)
+			{{- end }}
+			if err != nil {
+				return {{ $.HelperPrefix }}ToolError(ctx, err)
+			}
+			{{ if eq $.Output "text" }}
+			{{- if and (wellKnown $method.Output) $method.Output.Fields }}
+			// Well-known types are returned in their JSON form, such as the
+			// RFC 3339 string of a Timestamp, rather than field by field.
+			out, err := {{ protojson "MarshalOptions" }}{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			return {{ mcp "NewToolResultText" }}(string(out)), nil
+			{{- else }}
+			result := &{{ mcp "CallToolResult" }}{
+				Result:  {{ mcp "Result" }}{},
+				Content: []{{ mcp "Content" }}{},
 				IsError: false,
 			}
+			{{- if mapFields $method.Output }}
+			// Map fields are sliced out of the protojson encoding of the
+			// response, so that their values follow the proto JSON mapping.
+			out, err := {{ protojson "MarshalOptions" }}{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			var fields map[string]{{ json "RawMessage" }}
+			if err := {{ json "Unmarshal" }}(out, &fields); err != nil {
+				return nil, err
+			}
+			{{- end }}
 			
 			{{- range $field := $method.Output.Fields }}
-			{{- if isRepeated $field }}
+			{{- if mimeType $field }}
+			{{- template "mimeContent" $field }}
+			{{- else if isRepeated $field }}
 			// Format repeated field
-			if len(res.{{ $field.GoName }}) > 0 {
+			if len(res.Get{{ $field.GoName }}()) > 0 {
 				arrayStr := "["
-				for i, v := range res.{{ $field.GoName }} {
+				for i, v := range res.Get{{ $field.GoName }}() {
 					if i > 0 {
 						arrayStr += ", "
 					}
-					{{- if eq (getBaseType $field) "string" }}
-					arrayStr += fmt.Sprintf("%q", v)
+					{{- if $field.Message }}
+					if data, err := {{ protojson "Marshal" }}(v); err == nil {
+						arrayStr += string(data)
+					}
+					{{- else if eq (getBaseType $field) "string" }}
+					arrayStr += {{ fmt "Sprintf" }}("%q", v)
+					{{- else if eq (getBaseType $field) "[]byte" }}
+					arrayStr += {{ fmt "Sprintf" }}("%q", {{ base64 "StdEncoding" }}.EncodeToString(v))
+					{{- else if is64Bit $field }}
+					arrayStr += {{ fmt "Sprintf" }}("\"%d\"", v)
 					{{- else }}
-					arrayStr += fmt.Sprintf("%v", v)
+					arrayStr += {{ fmt "Sprintf" }}("%v", v)
 					{{- end }}
 				}
 				arrayStr += "]"
-				result.Content = append(result.Content, mcp.NewTextContent("{{ $field.GoName }}: " + arrayStr))
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + arrayStr))
 			} else {
-				result.Content = append(result.Content, mcp.NewTextContent("{{ $field.GoName }}: []"))
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: []"))
+			}
+			{{- else if $field.Desc.IsMap }}
+			// Format map field
+			result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + string(fields["{{ $field.Desc.JSONName }}"])))
+			{{- else if $field.Message }}
+			// Format message field
+			if res.Get{{ $field.GoName }}() == nil {
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: null"))
+			} else if data, err := {{ protojson "Marshal" }}(res.Get{{ $field.GoName }}()); err == nil {
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + string(data)))
+			} else {
+				return nil, err
+			}
+			{{- else if $field.Desc.HasOptionalKeyword }}
+			// Format optional field, leaving it out when unset
+			if res.{{ $field.GoName }} != nil {
+				{{- if eq (fieldType $field) "string" }}
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + res.Get{{ $field.GoName }}()))
+				{{- else if eq (fieldType $field) "[]byte" }}
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + {{ base64 "StdEncoding" }}.EncodeToString(res.Get{{ $field.GoName }}())))
+				{{- else if is64Bit $field }}
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + {{ fmt "Sprintf" }}("\"%d\"", res.Get{{ $field.GoName }}())))
+				{{- else }}
+				result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + {{ fmt "Sprintf" }}("%v", res.Get{{ $field.GoName }}())))
+				{{- end }}
 			}
 			{{- else }}
 			// Format non-repeated field
 			{{- if eq (fieldType $field) "string" }}
-			result.Content = append(result.Content, mcp.NewTextContent("{{ $field.GoName }}: " + res.{{ $field.GoName }}))
+			result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + res.Get{{ $field.GoName }}()))
 			{{- else if eq (fieldType $field) "[]byte" }}
-			result.Content = append(result.Content, mcp.NewTextContent("{{ $field.GoName }}: " + string(res.{{ $field.GoName }})))
+			result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + {{ base64 "StdEncoding" }}.EncodeToString(res.Get{{ $field.GoName }}())))
+			{{- else if is64Bit $field }}
+			result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + {{ fmt "Sprintf" }}("\"%d\"", res.Get{{ $field.GoName }}())))
 			{{- else }}
-			result.Content = append(result.Content, mcp.NewTextContent("{{ $field.GoName }}: " + fmt.Sprintf("%v", res.{{ $field.GoName }})))
+			result.Content = append(result.Content, {{ mcp "NewTextContent" }}("{{ $field.GoName }}: " + {{ fmt "Sprintf" }}("%v", res.Get{{ $field.GoName }}())))
 			{{- end }}
 			{{- end }}
 			{{- end }}
 			
 			return result, nil
+			{{- end }}
+			{{- else }}
+			out, err := {{ protojson "MarshalOptions" }}{EmitDefaultValues: true}.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			{{- if mimeFields $method.Output }}
+			// Fields with a MIME type are returned as their own content instead
+			// of as base64 in the JSON.
+			var fields map[string]{{ json "RawMessage" }}
+			if err := {{ json "Unmarshal" }}(out, &fields); err != nil {
+				return nil, err
+			}
+			{{- range mimeFields $method.Output }}
+			delete(fields, "{{ .Desc.JSONName }}")
+			{{- end }}
+			if out, err = {{ json "Marshal" }}(fields); err != nil {
+				return nil, err
+			}
+			{{- if eq $.Output "structured" }}
+			result := {{ mcp "NewToolResultStructured" }}({{ json "RawMessage" }}(out), string(out))
+			{{- else }}
+			result := {{ mcp "NewToolResultText" }}(string(out))
+			{{- end }}
+			{{- range mimeFields $method.Output }}
+			{{- template "mimeContent" . }}
+			{{- end }}
+			return result, nil
+			{{- else if and (eq $.Output "structured") (wrapsOutput $method.Output) }}
+			// Structured content must be an object, which the JSON form of
+			// {{ $method.Output.Desc.FullName }} isn't, so it is returned as {"value": ...}.
+			out = append(append([]byte("{\"value\":"), out...), '}')
+			return {{ mcp "NewToolResultStructured" }}({{ json "RawMessage" }}(out), string(out)), nil
+			{{- else if eq $.Output "structured" }}
+			return {{ mcp "NewToolResultStructured" }}({{ json "RawMessage" }}(out), string(out)), nil
+			{{- else }}
+			return {{ mcp "NewToolResultText" }}(string(out)), nil
+			{{- end }}
+			{{- end }}
+			{{- end }}
+		},
+	)
+	{{- if bidiStream $method }}
+	s.AddTool(
+		{{ mcp "NewTool" }}(
+			{{ sessionTool $method "receive" }},
+			{{ mcp "WithDescription" }}({{ sessionDesc $method "receive" }}),
+			{{ mcp "WithToolAnnotation" }}({{ mcp "ToolAnnotation" }}{
+				Title: {{ sessionTitle $method "receive" }},
+				{{- range $hint := toolHints $method }}
+				{{ $hint }},
+				{{- end }}
+			}),
+			{{ mcp "WithString" }}("session", {{ mcp "Required" }}(), {{ mcp "Description" }}({{ sessionDesc $method "session" }})),
+			{{ mcp "WithNumber" }}("waitSeconds", {{ mcp "Description" }}("Seconds to wait for a message when none is queued"), {{ mcp "Min" }}(0)),
+			{{- if eq $.Output "structured" }}
+			{{ mcp "WithRawOutputSchema" }}({{ json "RawMessage" }}({{ outputSchema $method }})),
+			{{- end }}
+		),
+		func(ctx {{ context "Context" }}, request {{ mcp "CallToolRequest" }}) (*{{ mcp "CallToolResult" }}, error) {
+			id, _ := request.GetArguments()["session"].(string)
+			stream := {{ $sessions }}.get(ctx, id)
+			if stream == nil {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("unknown session %q", id)), nil
+			}
+			wait, _ := request.GetArguments()["waitSeconds"].(float64)
+			result, done := stream.result(ctx, {{ time "Duration" }}(wait*float64({{ time "Second" }})))
+			if done {
+				{{ $sessions }}.remove({{ $sessions }}.key(ctx, id))
+			}
+			return result, nil
 		},
 	)
+	s.AddTool(
+		{{ mcp "NewTool" }}(
+			{{ sessionTool $method "close" }},
+			{{ mcp "WithDescription" }}({{ sessionDesc $method "close" }}),
+			{{ mcp "WithToolAnnotation" }}({{ mcp "ToolAnnotation" }}{
+				Title: {{ sessionTitle $method "close" }},
+				{{- range $hint := toolHints $method }}
+				{{ $hint }},
+				{{- end }}
+			}),
+			{{ mcp "WithString" }}("session", {{ mcp "Required" }}(), {{ mcp "Description" }}({{ sessionDesc $method "session" }})),
+			{{- if eq $.Output "structured" }}
+			{{ mcp "WithRawOutputSchema" }}({{ json "RawMessage" }}({{ outputSchema $method }})),
+			{{- end }}
+		),
+		func(ctx {{ context "Context" }}, request {{ mcp "CallToolRequest" }}) (*{{ mcp "CallToolResult" }}, error) {
+			id, _ := request.GetArguments()["session"].(string)
+			stream := {{ $sessions }}.get(ctx, id)
+			if stream == nil {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("unknown session %q", id)), nil
+			}
+			// Wait for the method to return after its last message, giving up
+			// after the idle timeout.
+			stream.closeSend()
+			timer := {{ time "NewTimer" }}({{ idleTimeout }})
+			select {
+			case <-stream.finished:
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+			timer.Stop()
+			{{ $sessions }}.remove({{ $sessions }}.key(ctx, id))
+			result, _ := stream.result(ctx, 0)
+			return result, nil
+		},
+	)
+	{{- end }}
 	{{- end }}
 }
 {{- end }}
 
+{{- if .Services }}
+
+// {{ $.HelperPrefix }}Status returns the gRPC status of an error as google.rpc.Status
+// JSON, leaving out the details when some can't be encoded
+func {{ $.HelperPrefix }}Status(err error) {{ json "RawMessage" }} {
+	st := {{ status "Convert" }}(err).Proto()
+	out, merr := {{ protojson "Marshal" }}(st)
+	if merr != nil {
+		st.Details = nil
+		out, _ = {{ protojson "Marshal" }}(st)
+	}
+	return out
+}
+
+// {{ $.HelperPrefix }}ToolError converts an error returned by a service to a tool
+// error result carrying its gRPC status, so that the model sees it. Errors of
+// canceled tool calls remain protocol errors.
+func {{ $.HelperPrefix }}ToolError(ctx {{ context "Context" }}, err error) (*{{ mcp "CallToolResult" }}, error) {
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	st := {{ status "Convert" }}(err)
+	result := {{ mcp "NewToolResultError" }}(st.Code().String() + ": " + st.Message())
+	result.StructuredContent = {{ $.HelperPrefix }}Status(err)
+	return result, nil
+}
+
+{{- if hasInt64Params .Services }}
+
+// {{ $.HelperPrefix }}InexactInt reports whether the JSON value of a message holds a
+// 64-bit integer passed as a number beyond 2^53, and returns its path. MCP
+// servers decode JSON numbers into float64, which can't hold every such
+// integer, so larger values must be passed as strings.
+func {{ $.HelperPrefix }}InexactInt(md {{ protoreflect "MessageDescriptor" }}, value interface{}) (string, bool) {
+	switch md.FullName() {
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		n, ok := value.(float64)
+		return "", ok && {{ math "Abs" }}(n) >= 1<<53
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok || md.ParentFile().Package() == "google.protobuf" {
+		return "", false
+	}
+	for name, value := range fields {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName({{ protoreflect "Name" }}(name))
+		}
+		if fd == nil {
+			continue
+		}
+		// Check every value of the field, keyed by its path suffix
+		values := map[string]interface{}{"": value}
+		vd := fd
+		if fd.IsMap() {
+			vd = fd.MapValue()
+			entries, _ := value.(map[string]interface{})
+			values = make(map[string]interface{}, len(entries))
+			for key, value := range entries {
+				values["["+{{ strconv "Quote" }}(key)+"]"] = value
+			}
+		} else if fd.IsList() {
+			list, _ := value.([]interface{})
+			values = make(map[string]interface{}, len(list))
+			for i, value := range list {
+				values["["+{{ strconv "Itoa" }}(i)+"]"] = value
+			}
+		}
+		for suffix, value := range values {
+			var path string
+			var inexact bool
+			switch vd.Kind() {
+			case {{ protoreflect "Int64Kind" }}, {{ protoreflect "Sint64Kind" }}, {{ protoreflect "Sfixed64Kind" }}, {{ protoreflect "Uint64Kind" }}, {{ protoreflect "Fixed64Kind" }}:
+				n, ok := value.(float64)
+				inexact = ok && {{ math "Abs" }}(n) >= 1<<53
+			case {{ protoreflect "MessageKind" }}, {{ protoreflect "GroupKind" }}:
+				path, inexact = {{ $.HelperPrefix }}InexactInt(vd.Message(), value)
+			}
+			if inexact {
+				if path != "" {
+					path = "." + path
+				}
+				return fd.JSONName() + suffix + path, true
+			}
+		}
+	}
+	return "", false
+}
+{{- end }}
+{{- if hasEnumParams .Services }}
+
+// {{ $.HelperPrefix }}CheckEnums checks that the enums in the JSON value of a
+// message, at any depth, are names of their values
+{{- if .EnumNumbers }} or their numbers{{ end }}. Errors
+// name the path of the value, which follows prefix.
+func {{ $.HelperPrefix }}CheckEnums(md {{ protoreflect "MessageDescriptor" }}, value interface{}, prefix string) error {
+	fields, ok := value.(map[string]interface{})
+	if !ok || md.ParentFile().Package() == "google.protobuf" {
+		return nil
+	}
+	for name, value := range fields {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName({{ protoreflect "Name" }}(name))
+		}
+		if fd == nil {
+			continue
+		}
+		// Check every value of the field, keyed by its path suffix
+		values := map[string]interface{}{"": value}
+		vd := fd
+		if fd.IsMap() {
+			vd = fd.MapValue()
+			entries, _ := value.(map[string]interface{})
+			values = make(map[string]interface{}, len(entries))
+			for key, value := range entries {
+				values["["+{{ strconv "Quote" }}(key)+"]"] = value
+			}
+		} else if fd.IsList() {
+			list, _ := value.([]interface{})
+			values = make(map[string]interface{}, len(list))
+			for i, value := range list {
+				values["["+{{ strconv "Itoa" }}(i)+"]"] = value
+			}
+		}
+		for suffix, value := range values {
+			path := prefix + fd.JSONName() + suffix
+			switch vd.Kind() {
+			case {{ protoreflect "EnumKind" }}:
+				ed := vd.Enum()
+				known := true
+				switch value := value.(type) {
+				case string:
+					known = ed.Values().ByName({{ protoreflect "Name" }}(value)) != nil
+				case float64:
+					{{- if .EnumNumbers }}
+					known = value == float64(int32(value)) && ed.Values().ByNumber({{ protoreflect "EnumNumber" }}(value)) != nil
+					{{- else }}
+					known = false
+					{{- end }}
+				}
+				if !known {
+					names := make([]string, ed.Values().Len())
+					for i := range names {
+						names[i] = string(ed.Values().Get(i).Name())
+					}
+					text := {{ fmt "Sprint" }}(value)
+					if s, ok := value.(string); ok {
+						text = {{ strconv "Quote" }}(s)
+					}
+					return {{ fmt "Errorf" }}("invalid value %s for %s: must be one of %s{{ if .EnumNumbers }} or their numbers{{ end }}", text, path, {{ strings "Join" }}(names, ", "))
+				}
+			case {{ protoreflect "MessageKind" }}, {{ protoreflect "GroupKind" }}:
+				if err := {{ $.HelperPrefix }}CheckEnums(vd.Message(), value, path+"."); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+{{- end }}
+{{- with requiredMsgs .Services }}
+
+// {{ $.HelperPrefix }}Required lists the JSON names of the required fields of
+// the messages nested in tool arguments
+var {{ $.HelperPrefix }}Required = map[{{ protoreflect "FullName" }}][]string{
+	{{- range $msg := . }}
+	"{{ $msg.Desc.FullName }}": { {{- range $i, $field := requiredFields $msg }}{{ if $i }}, {{ end }}"{{ $field.Desc.JSONName }}"{{ end -}} },
+	{{- end }}
+}
+
+// {{ $.HelperPrefix }}MissingField returns the path, following prefix, of a
+// required field missing from the JSON value of a message or of a message
+// nested in it at any depth, or "" when none is
+func {{ $.HelperPrefix }}MissingField(md {{ protoreflect "MessageDescriptor" }}, value interface{}, prefix string) string {
+	fields, ok := value.(map[string]interface{})
+	if !ok || md.ParentFile().Package() == "google.protobuf" {
+		return ""
+	}
+	for _, name := range {{ $.HelperPrefix }}Required[md.FullName()] {
+		// Nested fields may be given under their proto name as well
+		if fields[name] == nil && fields[string(md.Fields().ByJSONName(name).Name())] == nil {
+			return prefix + name
+		}
+	}
+	for name, value := range fields {
+		fd := md.Fields().ByJSONName(name)
+		if fd == nil {
+			fd = md.Fields().ByName({{ protoreflect "Name" }}(name))
+		}
+		if fd == nil {
+			continue
+		}
+		// Check every value of the field, keyed by its path suffix
+		values := map[string]interface{}{"": value}
+		vd := fd
+		if fd.IsMap() {
+			vd = fd.MapValue()
+			entries, _ := value.(map[string]interface{})
+			values = make(map[string]interface{}, len(entries))
+			for key, value := range entries {
+				values["["+{{ strconv "Quote" }}(key)+"]"] = value
+			}
+		} else if fd.IsList() {
+			list, _ := value.([]interface{})
+			values = make(map[string]interface{}, len(list))
+			for i, value := range list {
+				values["["+{{ strconv "Itoa" }}(i)+"]"] = value
+			}
+		}
+		if vd.Kind() != {{ protoreflect "MessageKind" }} && vd.Kind() != {{ protoreflect "GroupKind" }} {
+			continue
+		}
+		for suffix, value := range values {
+			if path := {{ $.HelperPrefix }}MissingField(vd.Message(), value, prefix+fd.JSONName()+suffix+"."); path != "" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+{{- end }}
+{{- if hasBytesParams .Services }}
+
+// {{ $.HelperPrefix }}IsBase64 reports whether s is base64 with the standard or
+// URL-safe alphabet, padded or not, as protojson accepts for bytes fields
+func {{ $.HelperPrefix }}IsBase64(s string) bool {
+	enc := {{ base64 "StdEncoding" }}
+	if {{ strings "ContainsAny" }}(s, "-_") {
+		enc = {{ base64 "URLEncoding" }}
+	}
+	if len(s)%4 != 0 {
+		enc = enc.WithPadding({{ base64 "NoPadding" }})
+	}
+	_, err := enc.DecodeString(s)
+	return err == nil
+}
+{{- end }}
+
+func Register{{ .FileName }}Proto{{ .InterfaceSuffix }}s(
+s *{{ server "MCPServer" }},
+{{- range $service := .Services }}
+srv{{ $service.GoName }} {{ $service.GoName }}{{ $.InterfaceSuffix }},
+{{- end }}
+) {
+{{- range $service := .Services }}
+	Register{{ $service.GoName }}{{ $.InterfaceSuffix }}(s, srv{{ $service.GoName }})
+{{- end }}
+}
+{{- end }}
+
+{{- define "decodeArgs" }}
+{{- /* Decodes the map in into req, the request message of .Method, checking
+	the arguments protojson doesn't. Errors about a client-streaming item
+	name its index i. */}}
+{{- $method := .Method }}
+{{- $at := "" }}
+{{- $i := "" }}
+{{- if .Item }}
+{{- $at = "invalid items[%d]: " }}
+{{- $i = "i, " }}
+{{- end }}
+			// Rename the parameters to the proto JSON names, so that the proto
+			// and Go field names are accepted as well.
+			args := make(map[string]interface{}, len(in))
+			for name, value := range in {
+				{{- if and (not .Item) (bidiStream $method) }}
+				if name == "session" {
+					continue
+				}
+				{{- end }}
+				{{- with argRenames $method }}
+				switch name {
+				{{- range $field := . }}
+				case {{ range $i, $alias := argAliases $field }}{{ if $i }}, {{ end }}"{{ $alias }}"{{ end }}:
+					name = "{{ $field.Desc.JSONName }}"
+				{{- end }}
+				}
+				if _, ok := args[name]; ok {
+					return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ if $.Item }}invalid items[%d]: field{{ else }}invalid arguments:{{ end }} %s is given under more than one name", {{ $i }}name)), nil
+				}
+				{{- end }}
+				args[name] = value
+			}
+			{{- if reachesEnum $method.Input }}
+			if err := {{ .File.HelperPrefix }}CheckEnums((&{{ ident $method.Input.GoIdent }}{}).ProtoReflect().Descriptor(), args, ""); err != nil {
+				return {{ mcp "NewToolResultError" }}({{ if .Item }}{{ fmt "Sprintf" }}("invalid items[%d]: %v", i, err){{ else }}err.Error(){{ end }}), nil
+			}
+			{{- end }}
+			{{- range $field := $method.Input.Fields }}
+			{{- if isBytes $field }}
+			{{- if isRepeated $field }}
+			if values, ok := args["{{ $field.Desc.JSONName }}"].([]interface{}); ok {
+				for _, value := range values {
+					if value, ok := value.(string); ok && !{{ $.File.HelperPrefix }}IsBase64(value) {
+						return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ $at }}invalid value for {{ paramName $field }}: must be base64 with the standard or URL-safe alphabet"{{ if $.Item }}, i{{ end }})), nil
+					}
+				}
+			}
+			{{- else }}
+			if value, ok := args["{{ $field.Desc.JSONName }}"].(string); ok && !{{ $.File.HelperPrefix }}IsBase64(value) {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ $at }}invalid value for {{ paramName $field }}: must be base64 with the standard or URL-safe alphabet"{{ if $.Item }}, i{{ end }})), nil
+			}
+			{{- end }}
+			{{- end }}
+			{{- end }}
+			{{- if reaches64Bit $method.Input }}
+			if path, ok := {{ .File.HelperPrefix }}InexactInt((&{{ ident $method.Input.GoIdent }}{}).ProtoReflect().Descriptor(), args); ok {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ $at }}invalid value for %s: integers beyond 2^53 must be passed as decimal strings", {{ $i }}path)), nil
+			}
+			{{- end }}
+			{{- with requiredFields $method.Input }}
+			missing := ""
+			{{- range $field := . }}
+			if v, ok := args["{{ $field.Desc.JSONName }}"]; !ok || v == nil {
+				missing += ", {{ paramName $field }}"
+			}
+			{{- end }}
+			if missing != "" {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ if $.Item }}invalid items[%d]: missing required fields{{ else }}missing required arguments{{ end }}: %s", {{ $i }}missing[2:])), nil
+			}
+			{{- end }}
+			{{- if nestedRequired $method.Input }}
+			if path := {{ .File.HelperPrefix }}MissingField((&{{ ident $method.Input.GoIdent }}{}).ProtoReflect().Descriptor(), args, ""); path != "" {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ $at }}missing required field %s", {{ $i }}path)), nil
+			}
+			{{- end }}
+			{{- range $oneof := oneofs $method.Input }}
+			n{{ $oneof.GoName }} := 0
+			for _, name := range []string{ {{- range $i, $field := $oneof.Fields }}{{ if $i }}, {{ end }}"{{ $field.Desc.JSONName }}"{{ end -}} } {
+				if v, ok := args[name]; ok && v != nil {
+					n{{ $oneof.GoName }}++
+				}
+			}
+			if n{{ $oneof.GoName }} > 1 {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ $at }}only one of {{ paramNames $oneof.Fields }} may be set"{{ if $.Item }}, i{{ end }})), nil
+			}
+			{{- end }}
+			req := &{{ ident $method.Input.GoIdent }}{}
+			data, err := {{ json "Marshal" }}(args)
+			if err != nil {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ if .Item }}invalid items[%d]{{ else }}invalid arguments{{ end }}: %v", {{ $i }}err)), nil
+			}
+			if err := ({{ protojson "UnmarshalOptions" }}{DiscardUnknown: {{ .File.DiscardUnknown }}}).Unmarshal(data, req); err != nil {
+				return {{ mcp "NewToolResultError" }}({{ fmt "Sprintf" }}("{{ if .Item }}invalid items[%d]{{ else }}invalid arguments{{ end }}: %v", {{ $i }}err)), nil
+			}
+			{{- if .File.Validate }}
+			if err := {{ protovalidate "Validate" }}(req); err != nil {
+				var verr *{{ protovalidate "ValidationError" }}
+				if !{{ errors "As" }}(err, &verr) {
+					return nil, err
+				}
+				violations := make([]map[string]interface{}, len(verr.Violations))
+				for j, v := range verr.Violations {
+					violations[j] = map[string]interface{}{
+						{{- if .Item }}
+						"item":    i,
+						{{- end }}
+						"field":   {{ protovalidate "FieldPathString" }}(v.Proto.GetField()),
+						"rule":    v.Proto.GetRuleId(),
+						"message": v.Proto.GetMessage(),
+					}
+				}
+				result := {{ mcp "NewToolResultStructured" }}(map[string]interface{}{"violations": violations}, {{ fmt "Sprintf" }}("{{ if .Item }}invalid items[%d]{{ else }}invalid arguments{{ end }}: %v", {{ $i }}err))
+				result.IsError = true
+				return result, nil
+			}
+			{{- end }}
+{{- end }}
+
+{{- define "mimeContent" }}
+			{{- if isRepeated . }}
+			for i := range res.Get{{ .GoName }}() {
+				data := res.Get{{ .GoName }}()[i]
+				result.Content = append(result.Content, {{ mimeContent . }})
+			}
+			{{- else }}
+			if data := res.Get{{ .GoName }}(); len(data) > 0 {
+				result.Content = append(result.Content, {{ mimeContent . }})
+			}
+			{{- end }}
+{{- end }}
+`
+
+// mcpPackageTemplate declares the functions serving all the services of a Go
+// package, generated once per package
+const mcpPackageTemplate = `
+// Code generated by protoc-gen-mcpserver. DO NOT EDIT.
+package {{ .PackageName }}
+
+type McpServices struct {
+{{- range $service := .Services }}
+	{{ $service.GoName }} {{ $service.GoName }}{{ $.InterfaceSuffix }}
+{{- end }}
+}
+
+func RegisterMcpServices(s *{{ server "MCPServer" }}, srvs McpServices) {
+{{- range $service := .Services }}
+	if srvs.{{ $service.GoName }} != nil {
+		Register{{ $service.GoName }}{{ $.InterfaceSuffix }}(s, srvs.{{ $service.GoName }})
+	}
+{{- end }}
+}
+
+func NewMcpServer(name, version string, srvs McpServices, opts ...{{ server "ServerOption" }}) *{{ server "MCPServer" }} {
+	opts = append([]{{ server "ServerOption" }}{
+		{{ server "WithToolCapabilities" }}(true),
+		{{ server "WithLogging" }}(),
+	}, opts...)
+	s := {{ server "NewMCPServer" }}(name, version, opts...)
+	RegisterMcpServices(s, srvs)
+	return s
+}
+
 func ServeStdio(
 name, 
 version string, 
 {{- range $service := .Services }}
-srv{{ $service.GoName }} {{ $service.GoName }}McpServer,
+srv{{ $service.GoName }} {{ $service.GoName }}{{ $.InterfaceSuffix }},
 {{- end }}
 ) error {
-	s := server.NewMCPServer(name, version,
-		server.WithToolCapabilities(true),
-		server.WithLogging(),
-	)
-{{- range $service := .Services }}
-	Register{{ $service.GoName }}McpServer(s, srv{{ $service.GoName }})
-{{- end }}
-	return server.ServeStdio(s)
+	return {{ server "ServeStdio" }}(NewMcpServer(name, version, McpServices{
+	{{- range $service := .Services }}
+		{{ $service.GoName }}: srv{{ $service.GoName }},
+	{{- end }}
+	}))
 }
 `
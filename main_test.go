@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// newPlugin compiles the given proto files from testdata and returns a plugin
+// run generating them with the parameters, parsed the way the plugin does
+func newPlugin(t *testing.T, params string, files ...string) (*protogen.Plugin, config, error) {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"testdata", "."}}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String(params),
+	}
+	seen := make(map[string]bool)
+	var add func(file protoreflect.FileDescriptor)
+	add = func(file protoreflect.FileDescriptor) {
+		if seen[file.Path()] {
+			return
+		}
+		seen[file.Path()] = true
+		imports := file.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(file))
+	}
+	for _, file := range compiled {
+		add(file)
+	}
+	// protoc sends the request encoded, which resolves the options of the
+	// compiled files against the extensions linked into the plugin
+	b, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = new(pluginpb.CodeGeneratorRequest)
+	if err := proto.Unmarshal(b, req); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg config
+	flags := cfg.flags()
+	var paramErr error
+	gen, err := protogen.Options{
+		ParamFunc: func(name, value string) error {
+			if err := setParam(flags, name, value); err != nil && paramErr == nil {
+				paramErr = err
+			}
+			return nil
+		},
+	}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen, cfg, paramErr
+}
+
+// generateFiles runs the plugin and protoc-gen-go over the given files and
+// returns the generated files by name
+func generateFiles(t *testing.T, params string, files ...string) map[string]string {
+	t.Helper()
+	gen, cfg, err := newPlugin(t, params, files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(gen, cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range gen.Files {
+		if file.Generate {
+			internal_gengo.GenerateFile(gen, file)
+		}
+	}
+	resp := gen.Response()
+	if resp.Error != nil {
+		t.Fatal(resp.GetError())
+	}
+	out := make(map[string]string)
+	for _, file := range resp.File {
+		out[file.GetName()] = file.GetContent()
+	}
+	return out
+}
+
+// findMessage returns a message of the files of a plugin by full name
+func findMessage(t *testing.T, gen *protogen.Plugin, name protoreflect.FullName) *protogen.Message {
+	t.Helper()
+	var find func(msgs []*protogen.Message) *protogen.Message
+	find = func(msgs []*protogen.Message) *protogen.Message {
+		for _, msg := range msgs {
+			if msg.Desc.FullName() == name {
+				return msg
+			}
+			if found := find(msg.Messages); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	for _, file := range gen.Files {
+		if msg := find(file.Messages); msg != nil {
+			return msg
+		}
+	}
+	t.Fatalf("no message %s", name)
+	return nil
+}
+
+// findMethod returns a method of the files of a plugin by full name
+func findMethod(t *testing.T, gen *protogen.Plugin, name protoreflect.FullName) *protogen.Method {
+	t.Helper()
+	for _, file := range gen.Files {
+		for _, service := range file.Services {
+			for _, method := range service.Methods {
+				if method.Desc.FullName() == name {
+					return method
+				}
+			}
+		}
+	}
+	t.Fatalf("no method %s", name)
+	return nil
+}
+
+// findField returns a field of a message by proto name
+func findField(t *testing.T, msg *protogen.Message, name protoreflect.Name) *protogen.Field {
+	t.Helper()
+	for _, field := range msg.Fields {
+		if field.Desc.Name() == name {
+			return field
+		}
+	}
+	t.Fatalf("no field %s in %s", name, msg.Desc.FullName())
+	return nil
+}
+
+func TestGolden(t *testing.T) {
+	files := generateFiles(t, "bidi_sessions=true", "golden/tree.proto", "golden/2fa.proto")
+	for _, name := range []string{"golden/pb/tree.mcpserver.go", "golden/pb/2fa.mcpserver.go", "golden/pb/pb.mcpserver.pkg.go"} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("%s not generated", name)
+			continue
+		}
+		golden := filepath.Join("testdata", "golden", filepath.Base(name)+".golden")
+		if *update {
+			if err := os.WriteFile(golden, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content != string(want) {
+			t.Errorf("%s differs from %s; run go test -run TestGolden -update to update it", name, golden)
+		}
+	}
+}
+
+// TestGeneratedCode builds the code generated for the golden protos with
+// several parameter sets in a module of its own, and runs
+// testdata/golden/server_test.go against it, which calls the tools.
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated code")
+	}
+	root, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod, err := os.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile("go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTest, err := os.ReadFile(filepath.Join("testdata", "golden", "server_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, params := range []string{
+		"bidi_sessions=true",
+		"bidi_sessions=true,output=text",
+		"bidi_sessions=true,output=structured",
+		"bidi_sessions=true,field_naming=go,tool_naming=snake,enum_numbers=true,discard_unknown=true",
+		"bidi_sessions=true,validate=true",
+	} {
+		t.Run(params, func(t *testing.T) {
+			dir := t.TempDir()
+			mod := strings.Replace(string(goMod), "module github.com/wricardo/protoc-gen-mcpserver", "module golden", 1) +
+				"\nrequire github.com/wricardo/protoc-gen-mcpserver v0.0.0\n\nreplace github.com/wricardo/protoc-gen-mcpserver => " + root + "\n" +
+				// tree.proto has protovalidate rules, so its Go code imports the
+				// validate package, and validate=true imports protovalidate
+				"\nrequire buf.build/go/protovalidate v1.4.0\n"
+			write := func(name string, content []byte) {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write("go.mod", []byte(mod))
+			write("go.sum", goSum)
+			write("pb/server_test.go", serverTest)
+			for name, content := range generateFiles(t, params, "golden/tree.proto", "golden/2fa.proto") {
+				write(strings.TrimPrefix(name, "golden/"), []byte(content))
+			}
+			for _, args := range [][]string{{"vet", "./..."}, {"test", "./..."}} {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = dir
+				cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOLDEN_PARAMS="+params)
+				var out bytes.Buffer
+				cmd.Stdout, cmd.Stderr = &out, &out
+				if err := cmd.Run(); err != nil {
+					t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tt := range []struct {
+		params string
+		file   string
+		want   string
+	}{
+		{"", "errors/wkt_request.proto", "errors/wkt_request.proto:10:3: errors.Counter.Add: google.protobuf.Int64Value requests are only supported by client-streaming RPCs"},
+		{"", "errors/session_field.proto", "errors/session_field.proto:8:3: errors.Chat.Chat: bidirectional streaming RPCs are only supported with bidi_sessions=true"},
+		{"bidi_sessions=true", "errors/session_field.proto", "errors/session_field.proto:12:3: errors.Message.session: field collides with the session parameter of the Chat_send tool"},
+		{"", "errors/mime_type.proto", "errors/mime_type.proto:14:3: errors.Picture.png: mime_type is only supported on bytes fields"},
+	} {
+		gen, cfg, err := newPlugin(t, tt.params, tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := generate(gen, cfg); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("generating %s with %q: got error %v, want %q", tt.file, tt.params, err, tt.want)
+		}
+	}
+}
+
+func TestHelperPrefix(t *testing.T) {
+	for name, want := range map[string]string{
+		"UserService": "userServiceProto",
+		"HTTPApi":     "httpApiProto",
+		"2fa":         "file2faProto",
+		"":            "Proto",
+	} {
+		if got := helperPrefix(name); got != want {
+			t.Errorf("helperPrefix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGoCamelCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"user_service": "UserService",
+		"2fa":          "2fa",
+		"a.b-c":        "ABC",
+	} {
+		if got := goCamelCase(name); got != want {
+			t.Errorf("goCamelCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
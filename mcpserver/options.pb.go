@@ -0,0 +1,444 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: mcpserver/options.proto
+
+package mcpserver
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ToolAnnotations are the MCP behaviour hints attached to a tool. Hints that
+// are not set keep the value the generated code would otherwise use.
+type ToolAnnotations struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The tool does not modify its environment.
+	ReadOnlyHint *bool `protobuf:"varint,1,opt,name=read_only_hint,json=readOnlyHint,proto3,oneof" json:"read_only_hint,omitempty"`
+	// The tool may perform destructive updates.
+	DestructiveHint *bool `protobuf:"varint,2,opt,name=destructive_hint,json=destructiveHint,proto3,oneof" json:"destructive_hint,omitempty"`
+	// Repeated calls with the same arguments have no additional effect.
+	IdempotentHint *bool `protobuf:"varint,3,opt,name=idempotent_hint,json=idempotentHint,proto3,oneof" json:"idempotent_hint,omitempty"`
+	// The tool interacts with an open world of external entities.
+	OpenWorldHint *bool `protobuf:"varint,4,opt,name=open_world_hint,json=openWorldHint,proto3,oneof" json:"open_world_hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolAnnotations) Reset() {
+	*x = ToolAnnotations{}
+	mi := &file_mcpserver_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolAnnotations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolAnnotations) ProtoMessage() {}
+
+func (x *ToolAnnotations) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpserver_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolAnnotations.ProtoReflect.Descriptor instead.
+func (*ToolAnnotations) Descriptor() ([]byte, []int) {
+	return file_mcpserver_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ToolAnnotations) GetReadOnlyHint() bool {
+	if x != nil && x.ReadOnlyHint != nil {
+		return *x.ReadOnlyHint
+	}
+	return false
+}
+
+func (x *ToolAnnotations) GetDestructiveHint() bool {
+	if x != nil && x.DestructiveHint != nil {
+		return *x.DestructiveHint
+	}
+	return false
+}
+
+func (x *ToolAnnotations) GetIdempotentHint() bool {
+	if x != nil && x.IdempotentHint != nil {
+		return *x.IdempotentHint
+	}
+	return false
+}
+
+func (x *ToolAnnotations) GetOpenWorldHint() bool {
+	if x != nil && x.OpenWorldHint != nil {
+		return *x.OpenWorldHint
+	}
+	return false
+}
+
+// ServiceOptions configure every tool generated for a service.
+type ServiceOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Prefix prepended to the name of every tool of the service.
+	NamePrefix string `protobuf:"bytes,1,opt,name=name_prefix,json=namePrefix,proto3" json:"name_prefix,omitempty"`
+	// Default annotations for every tool of the service. Methods can override
+	// individual hints.
+	Annotations   *ToolAnnotations `protobuf:"bytes,2,opt,name=annotations,proto3" json:"annotations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceOptions) Reset() {
+	*x = ServiceOptions{}
+	mi := &file_mcpserver_options_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceOptions) ProtoMessage() {}
+
+func (x *ServiceOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpserver_options_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceOptions.ProtoReflect.Descriptor instead.
+func (*ServiceOptions) Descriptor() ([]byte, []int) {
+	return file_mcpserver_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServiceOptions) GetNamePrefix() string {
+	if x != nil {
+		return x.NamePrefix
+	}
+	return ""
+}
+
+func (x *ServiceOptions) GetAnnotations() *ToolAnnotations {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+// MethodOptions configure the tool generated for a method.
+type MethodOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tool name. Defaults to the method name.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Human-readable tool title. Defaults to the method name.
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// Tool description. Defaults to the method comments.
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// Behaviour hints for the tool.
+	Annotations   *ToolAnnotations `protobuf:"bytes,4,opt,name=annotations,proto3" json:"annotations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodOptions) Reset() {
+	*x = MethodOptions{}
+	mi := &file_mcpserver_options_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodOptions) ProtoMessage() {}
+
+func (x *MethodOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpserver_options_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodOptions.ProtoReflect.Descriptor instead.
+func (*MethodOptions) Descriptor() ([]byte, []int) {
+	return file_mcpserver_options_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MethodOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MethodOptions) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *MethodOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MethodOptions) GetAnnotations() *ToolAnnotations {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+// FieldOptions configure the tool parameter generated for a field.
+type FieldOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Parameter description. Defaults to the field comments.
+	Description string `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	// Mark the parameter as required in the tool input schema.
+	Required bool `protobuf:"varint,2,opt,name=required,proto3" json:"required,omitempty"`
+	// Example values for the parameter. Examples of bool and numeric fields
+	// are emitted as JSON booleans and numbers.
+	Examples []string `protobuf:"bytes,3,rep,name=examples,proto3" json:"examples,omitempty"`
+	// MIME type of a bytes field of a response message, such as "image/png".
+	// The field is returned as image content for image/* types, audio content
+	// for audio/* types, or an embedded blob resource otherwise, instead of as
+	// base64 in the JSON response.
+	MimeType      string `protobuf:"bytes,4,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldOptions) Reset() {
+	*x = FieldOptions{}
+	mi := &file_mcpserver_options_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldOptions) ProtoMessage() {}
+
+func (x *FieldOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_mcpserver_options_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldOptions.ProtoReflect.Descriptor instead.
+func (*FieldOptions) Descriptor() ([]byte, []int) {
+	return file_mcpserver_options_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FieldOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *FieldOptions) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *FieldOptions) GetExamples() []string {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+func (x *FieldOptions) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+var file_mcpserver_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*ServiceOptions)(nil),
+		Field:         52000,
+		Name:          "mcpserver.service",
+		Tag:           "bytes,52000,opt,name=service",
+		Filename:      "mcpserver/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*MethodOptions)(nil),
+		Field:         52000,
+		Name:          "mcpserver.method",
+		Tag:           "bytes,52000,opt,name=method",
+		Filename:      "mcpserver/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*FieldOptions)(nil),
+		Field:         52000,
+		Name:          "mcpserver.field",
+		Tag:           "bytes,52000,opt,name=field",
+		Filename:      "mcpserver/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.ServiceOptions.
+var (
+	// optional mcpserver.ServiceOptions service = 52000;
+	E_Service = &file_mcpserver_options_proto_extTypes[0]
+)
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional mcpserver.MethodOptions method = 52000;
+	E_Method = &file_mcpserver_options_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional mcpserver.FieldOptions field = 52000;
+	E_Field = &file_mcpserver_options_proto_extTypes[2]
+)
+
+var File_mcpserver_options_proto protoreflect.FileDescriptor
+
+const file_mcpserver_options_proto_rawDesc = "" +
+	"\n" +
+	"\x17mcpserver/options.proto\x12\tmcpserver\x1a google/protobuf/descriptor.proto\"\x97\x02\n" +
+	"\x0fToolAnnotations\x12)\n" +
+	"\x0eread_only_hint\x18\x01 \x01(\bH\x00R\freadOnlyHint\x88\x01\x01\x12.\n" +
+	"\x10destructive_hint\x18\x02 \x01(\bH\x01R\x0fdestructiveHint\x88\x01\x01\x12,\n" +
+	"\x0fidempotent_hint\x18\x03 \x01(\bH\x02R\x0eidempotentHint\x88\x01\x01\x12+\n" +
+	"\x0fopen_world_hint\x18\x04 \x01(\bH\x03R\ropenWorldHint\x88\x01\x01B\x11\n" +
+	"\x0f_read_only_hintB\x13\n" +
+	"\x11_destructive_hintB\x12\n" +
+	"\x10_idempotent_hintB\x12\n" +
+	"\x10_open_world_hint\"o\n" +
+	"\x0eServiceOptions\x12\x1f\n" +
+	"\vname_prefix\x18\x01 \x01(\tR\n" +
+	"namePrefix\x12<\n" +
+	"\vannotations\x18\x02 \x01(\v2\x1a.mcpserver.ToolAnnotationsR\vannotations\"\x99\x01\n" +
+	"\rMethodOptions\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12<\n" +
+	"\vannotations\x18\x04 \x01(\v2\x1a.mcpserver.ToolAnnotationsR\vannotations\"\x85\x01\n" +
+	"\fFieldOptions\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x02 \x01(\bR\brequired\x12\x1a\n" +
+	"\bexamples\x18\x03 \x03(\tR\bexamples\x12\x1b\n" +
+	"\tmime_type\x18\x04 \x01(\tR\bmimeType:V\n" +
+	"\aservice\x12\x1f.google.protobuf.ServiceOptions\x18\xa0\x96\x03 \x01(\v2\x19.mcpserver.ServiceOptionsR\aservice:R\n" +
+	"\x06method\x12\x1e.google.protobuf.MethodOptions\x18\xa0\x96\x03 \x01(\v2\x18.mcpserver.MethodOptionsR\x06method:N\n" +
+	"\x05field\x12\x1d.google.protobuf.FieldOptions\x18\xa0\x96\x03 \x01(\v2\x17.mcpserver.FieldOptionsR\x05fieldB4Z2github.com/wricardo/protoc-gen-mcpserver/mcpserverb\x06proto3"
+
+var (
+	file_mcpserver_options_proto_rawDescOnce sync.Once
+	file_mcpserver_options_proto_rawDescData []byte
+)
+
+func file_mcpserver_options_proto_rawDescGZIP() []byte {
+	file_mcpserver_options_proto_rawDescOnce.Do(func() {
+		file_mcpserver_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mcpserver_options_proto_rawDesc), len(file_mcpserver_options_proto_rawDesc)))
+	})
+	return file_mcpserver_options_proto_rawDescData
+}
+
+var file_mcpserver_options_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_mcpserver_options_proto_goTypes = []any{
+	(*ToolAnnotations)(nil),             // 0: mcpserver.ToolAnnotations
+	(*ServiceOptions)(nil),              // 1: mcpserver.ServiceOptions
+	(*MethodOptions)(nil),               // 2: mcpserver.MethodOptions
+	(*FieldOptions)(nil),                // 3: mcpserver.FieldOptions
+	(*descriptorpb.ServiceOptions)(nil), // 4: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 5: google.protobuf.MethodOptions
+	(*descriptorpb.FieldOptions)(nil),   // 6: google.protobuf.FieldOptions
+}
+var file_mcpserver_options_proto_depIdxs = []int32{
+	0, // 0: mcpserver.ServiceOptions.annotations:type_name -> mcpserver.ToolAnnotations
+	0, // 1: mcpserver.MethodOptions.annotations:type_name -> mcpserver.ToolAnnotations
+	4, // 2: mcpserver.service:extendee -> google.protobuf.ServiceOptions
+	5, // 3: mcpserver.method:extendee -> google.protobuf.MethodOptions
+	6, // 4: mcpserver.field:extendee -> google.protobuf.FieldOptions
+	1, // 5: mcpserver.service:type_name -> mcpserver.ServiceOptions
+	2, // 6: mcpserver.method:type_name -> mcpserver.MethodOptions
+	3, // 7: mcpserver.field:type_name -> mcpserver.FieldOptions
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	5, // [5:8] is the sub-list for extension type_name
+	2, // [2:5] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_mcpserver_options_proto_init() }
+func file_mcpserver_options_proto_init() {
+	if File_mcpserver_options_proto != nil {
+		return
+	}
+	file_mcpserver_options_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mcpserver_options_proto_rawDesc), len(file_mcpserver_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 3,
+			NumServices:   0,
+		},
+		GoTypes:           file_mcpserver_options_proto_goTypes,
+		DependencyIndexes: file_mcpserver_options_proto_depIdxs,
+		MessageInfos:      file_mcpserver_options_proto_msgTypes,
+		ExtensionInfos:    file_mcpserver_options_proto_extTypes,
+	}.Build()
+	File_mcpserver_options_proto = out.File
+	file_mcpserver_options_proto_goTypes = nil
+	file_mcpserver_options_proto_depIdxs = nil
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/wricardo/protoc-gen-mcpserver/mcpserver"
+)
+
+// serviceOptions returns the (mcpserver.service) options of a service
+func serviceOptions(service *protogen.Service) *mcpserver.ServiceOptions {
+	opts, _ := proto.GetExtension(service.Desc.Options(), mcpserver.E_Service).(*mcpserver.ServiceOptions)
+	return opts
+}
+
+// methodOptions returns the (mcpserver.method) options of a method
+func methodOptions(method *protogen.Method) *mcpserver.MethodOptions {
+	opts, _ := proto.GetExtension(method.Desc.Options(), mcpserver.E_Method).(*mcpserver.MethodOptions)
+	return opts
+}
+
+// fieldOptions returns the (mcpserver.field) options of a field
+func fieldOptions(field *protogen.Field) *mcpserver.FieldOptions {
+	opts, _ := proto.GetExtension(field.Desc.Options(), mcpserver.E_Field).(*mcpserver.FieldOptions)
+	return opts
+}
+
+// customOption returns the value of the custom option with the given full
+// name set on a descriptor. The extension is resolved among the files imported
+// by the descriptor's file, so options declared in proto files the plugin
+// doesn't link (such as google.api.field_behavior) can be read. ok is false
+// when the option is not declared or not set.
+func customOption(desc protoreflect.Descriptor, name protoreflect.FullName) (value protoreflect.Value, ok bool) {
+	xd := findExtension(desc.ParentFile(), name, make(map[string]bool))
+	if xd == nil {
+		return protoreflect.Value{}, false
+	}
+	xt := dynamicpb.NewExtensionType(xd)
+	types := new(protoregistry.Types)
+	if err := types.RegisterExtension(xt); err != nil {
+		return protoreflect.Value{}, false
+	}
+	// Re-parse the options so the extension is decoded with the resolved
+	// type rather than kept as unknown fields.
+	b, err := proto.Marshal(desc.Options())
+	if err != nil {
+		return protoreflect.Value{}, false
+	}
+	opts := desc.Options().ProtoReflect().New()
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(b, opts.Interface()); err != nil {
+		return protoreflect.Value{}, false
+	}
+	if !opts.Has(xt.TypeDescriptor()) {
+		return protoreflect.Value{}, false
+	}
+	return opts.Get(xt.TypeDescriptor()), true
+}
+
+// findExtension looks up an extension by full name in a file and the files it
+// imports
+func findExtension(file protoreflect.FileDescriptor, name protoreflect.FullName, seen map[string]bool) protoreflect.ExtensionDescriptor {
+	if seen[file.Path()] {
+		return nil
+	}
+	seen[file.Path()] = true
+	if file.Package() == name.Parent() {
+		if xd := file.Extensions().ByName(name.Name()); xd != nil {
+			return xd
+		}
+	}
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if xd := findExtension(imports.Get(i).FileDescriptor, name, seen); xd != nil {
+			return xd
+		}
+	}
+	return nil
+}
+
+// isRequired checks if a field must be set by the caller. A field is required
+// when marked with (mcpserver.field).required, with
+// (google.api.field_behavior) = REQUIRED, or with the protovalidate
+// (buf.validate.field).required rule. Fields declared with proto3 optional
+// never are, whatever their annotations, since their presence is up to the
+// caller.
+func isRequired(field *protogen.Field) bool {
+	if field.Desc.HasOptionalKeyword() {
+		return false
+	}
+	if fieldOptions(field).GetRequired() {
+		return true
+	}
+	if behaviors, ok := customOption(field.Desc, "google.api.field_behavior"); ok {
+		list := behaviors.List()
+		for i := 0; i < list.Len(); i++ {
+			if list.Get(i).Enum() == 2 { // google.api.FieldBehavior.REQUIRED
+				return true
+			}
+		}
+	}
+	if rules, ok := customOption(field.Desc, "buf.validate.field"); ok {
+		if fd := rules.Message().Descriptor().Fields().ByName("required"); fd != nil {
+			return rules.Message().Get(fd).Bool()
+		}
+	}
+	return false
+}
+
+// toolName returns the name a method is registered under, following the
+// tool_naming strategy unless the method name is overridden, and prepending
+// the service name prefix
+func toolName(cfg config, method *protogen.Method) string {
+	name := methodOptions(method).GetName()
+	if name == "" {
+		switch cfg.ToolNaming {
+		case "snake":
+			name = snakeCase(string(method.Desc.Name()))
+		case "camel":
+			name = lowerCamelCase(string(method.Desc.Name()))
+		case "service":
+			name = string(method.Parent.Desc.Name()) + "." + string(method.Desc.Name())
+		case "qualified":
+			name = strings.ReplaceAll(string(method.Desc.FullName()), ".", "_")
+		default:
+			name = method.GoName
+		}
+	}
+	return serviceOptions(method.Parent).GetNamePrefix() + name
+}
+
+// sessionOps are the operations of the session tools generated for a
+// bidirectional streaming method, which are named after the method's tool
+// name followed by an underscore and the operation
+var sessionOps = []string{"open", "send", "receive", "close"}
+
+// toolNames returns the names of the tools generated for a method
+func toolNames(cfg config, method *protogen.Method) []string {
+	name := toolName(cfg, method)
+	if !isBidiStreaming(method) {
+		return []string{name}
+	}
+	names := make([]string, len(sessionOps))
+	for i, op := range sessionOps {
+		names[i] = name + "_" + op
+	}
+	return names
+}
+
+// validToolName matches the tool names allowed by the MCP specification
+var validToolName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// checkToolNames reports tool names that MCP doesn't allow and tools of
+// different methods sharing a name, which would replace each other when
+// registered on the same server
+func checkToolNames(cfg config, files []*protogen.File) error {
+	seen := make(map[string]*protogen.Method)
+	for _, file := range files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range cfg.services(file) {
+			for _, method := range service.Methods {
+				for _, name := range toolNames(cfg, method) {
+					if !validToolName.MatchString(name) {
+						return descriptorError(method.Desc, "invalid tool name %q: must be 1 to 128 letters, digits, '_', '-', or '.'", name)
+					}
+					if other, ok := seen[name]; ok {
+						return descriptorError(method.Desc, "tool name %q collides with %s; use tool_naming=qualified or set (mcpserver.method).name",
+							name, other.Desc.FullName())
+					}
+					seen[name] = method
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// snakeCase converts a CamelCase name to snake_case, keeping acronyms
+// together: "GetHTTPStatus" becomes "get_http_status"
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' &&
+				(!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lowerCamelCase lowercases the leading word of a CamelCase name, including a
+// leading acronym: "HTTPGet" becomes "httpGet"
+func lowerCamelCase(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) || i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// toolTitle returns the human-readable title of a method's tool
+func toolTitle(method *protogen.Method) string {
+	if t := methodOptions(method).GetTitle(); t != "" {
+		return strconv.Quote(t)
+	}
+	return strconv.Quote(method.GoName)
+}
+
+// sessionToolTitle returns the human-readable title of one of the session
+// tools of a bidirectional streaming method
+func sessionToolTitle(method *protogen.Method, op string) string {
+	title, _ := strconv.Unquote(toolTitle(method))
+	return strconv.Quote(title + " (" + op + ")")
+}
+
+// toolHints returns the ToolAnnotation hint fields set for a method, with
+// method annotations taking precedence over the service defaults
+func toolHints(g *protogen.GeneratedFile, method *protogen.Method) []string {
+	var hints []string
+	for _, h := range []struct {
+		name string
+		get  func(*mcpserver.ToolAnnotations) *bool
+	}{
+		{"ReadOnlyHint", func(a *mcpserver.ToolAnnotations) *bool { return a.ReadOnlyHint }},
+		{"DestructiveHint", func(a *mcpserver.ToolAnnotations) *bool { return a.DestructiveHint }},
+		{"IdempotentHint", func(a *mcpserver.ToolAnnotations) *bool { return a.IdempotentHint }},
+		{"OpenWorldHint", func(a *mcpserver.ToolAnnotations) *bool { return a.OpenWorldHint }},
+	} {
+		var value *bool
+		for _, a := range []*mcpserver.ToolAnnotations{
+			methodOptions(method).GetAnnotations(),
+			serviceOptions(method.Parent).GetAnnotations(),
+		} {
+			if a != nil && h.get(a) != nil {
+				value = h.get(a)
+				break
+			}
+		}
+		if value != nil {
+			hints = append(hints, h.name+": "+g.QualifiedGoIdent(mcpPackage.Ident("ToBoolPtr"))+"("+strconv.FormatBool(*value)+")")
+		}
+	}
+	return hints
+}
+
+// mimeFields returns the fields of a response message set as their own tool
+// result content because they have a mime_type option
+func mimeFields(msg *protogen.Message) []*protogen.Field {
+	var fields []*protogen.Field
+	for _, field := range msg.Fields {
+		if fieldOptions(field).GetMimeType() != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// mimeContent returns the mcp.Content expression holding the base64 encoding
+// of the variable data, an element of a field with a mime_type option. The
+// elements of repeated fields are told apart in resource URIs by the variable i.
+func mimeContent(g *protogen.GeneratedFile, field *protogen.Field) string {
+	mimeType := fieldOptions(field).GetMimeType()
+	encoded := g.QualifiedGoIdent(base64Package.Ident("StdEncoding")) + ".EncodeToString(data)"
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return g.QualifiedGoIdent(mcpPackage.Ident("NewImageContent")) + "(" + encoded + ", " + strconv.Quote(mimeType) + ")"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return g.QualifiedGoIdent(mcpPackage.Ident("NewAudioContent")) + "(" + encoded + ", " + strconv.Quote(mimeType) + ")"
+	}
+	uri := strconv.Quote("attachment://" + field.Desc.JSONName())
+	if isRepeated(field) {
+		uri += " + \"/\" + " + g.QualifiedGoIdent(strconvPackage.Ident("Itoa")) + "(i)"
+	}
+	return g.QualifiedGoIdent(mcpPackage.Ident("NewEmbeddedResource")) + "(" + g.QualifiedGoIdent(mcpPackage.Ident("BlobResourceContents")) +
+		"{URI: " + uri + ", MIMEType: " + strconv.Quote(mimeType) + ", Blob: " + encoded + "})"
+}
+
+// fieldPropertyOptions returns the extra mcp.PropertyOption arguments for a
+// field derived from its options, each preceded by a comma
+func fieldPropertyOptions(g *protogen.GeneratedFile, field *protogen.Field) string {
+	opts := fieldOptions(field)
+	var b strings.Builder
+	if isRequired(field) {
+		b.WriteString(mcpCall(g, "Required"))
+	}
+	if examples := opts.GetExamples(); len(examples) > 0 {
+		values := make([]interface{}, len(examples))
+		for i, example := range examples {
+			values[i] = exampleValue(field, example)
+		}
+		b.WriteString(setKeyword(g, "examples", values))
+	}
+	return b.String()
+}
+
+// exampleValue returns an example as a JSON value. Examples of bool and
+// numeric fields that parse as such become booleans and numbers; everything
+// else, including 64-bit integers, is a string.
+func exampleValue(field *protogen.Field, example string) interface{} {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		if v, err := strconv.ParseBool(example); err == nil {
+			return v
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		if v, err := strconv.ParseFloat(example, 64); err == nil {
+			return v
+		}
+	}
+	return example
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"GetHTTPStatus": "get_http_status",
+		"HTTPGet":       "http_get",
+		"ListV2Items":   "list_v2_items",
+		"Get_Item":      "get_item",
+		"get":           "get",
+	} {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLowerCamelCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"GetHTTPStatus": "getHTTPStatus",
+		"HTTPGet":       "httpGet",
+		"HTTP":          "http",
+		"Get":           "get",
+		"get":           "get",
+	} {
+		if got := lowerCamelCase(name); got != want {
+			t.Errorf("lowerCamelCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestToolName(t *testing.T) {
+	gen, _, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := findMethod(t, gen, "schematest.Things.GetHTTPStatus")
+	renamed := findMethod(t, gen, "schematest.Things.Describe")
+	for strategy, want := range map[string]string{
+		"method":    "things_GetHTTPStatus",
+		"snake":     "things_get_http_status",
+		"camel":     "things_getHTTPStatus",
+		"service":   "things_Things.GetHTTPStatus",
+		"qualified": "things_schematest_Things_GetHTTPStatus",
+	} {
+		cfg := config{ToolNaming: strategy}
+		if got := toolName(cfg, method); got != want {
+			t.Errorf("toolName with tool_naming=%s = %q, want %q", strategy, got, want)
+		}
+		if got := toolName(cfg, renamed); got != "things_describe_things" {
+			t.Errorf("toolName of a renamed method with tool_naming=%s = %q, want %q", strategy, got, "things_describe_things")
+		}
+	}
+}
+
+func TestCheckToolNames(t *testing.T) {
+	for _, tt := range []struct {
+		params string
+		file   string
+		want   string
+	}{
+		{"", "errors/collision.proto", `tool name "List" collides with errors.Alpha.List`},
+		{"tool_naming=service", "errors/collision.proto", ""},
+		{"", "errors/tool_name.proto", `invalid tool name "list things"`},
+	} {
+		gen, cfg, err := newPlugin(t, tt.params, tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = checkToolNames(cfg, gen.Files)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("checkToolNames(%s) with %q = %v, want %q", tt.file, tt.params, err, tt.want)
+		}
+	}
+}
+
+func TestArgAliases(t *testing.T) {
+	gen, _, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := findMessage(t, gen, "schematest.Naming")
+	for _, tt := range []struct {
+		field string
+		want  []string
+	}{
+		// a_b gets the Go name AB, the proto name of another field, so AB
+		// gets AB_.
+		{"a_b", []string{"a_b"}},
+		{"AB", []string{"AB_"}},
+		{"display_name", []string{"display_name", "DisplayName"}},
+		{"name", []string{"Name"}},
+	} {
+		if got := argAliases(findField(t, msg, protoreflect.Name(tt.field))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("argAliases(%s) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestIsRequired(t *testing.T) {
+	gen, _, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := findMessage(t, gen, "schematest.Required")
+	for field, want := range map[protoreflect.Name]bool{
+		"option":      true,
+		"behavior":    true,
+		"rule":        true,
+		"output_only": false,
+		"none":        false,
+		"optional":    false,
+	} {
+		if got := isRequired(findField(t, msg, field)); got != want {
+			t.Errorf("isRequired(%s) = %v, want %v", field, got, want)
+		}
+	}
+}
+
+func TestMimeFields(t *testing.T) {
+	gen, _, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := mimeFields(findMessage(t, gen, "schematest.Picture"))
+	if len(fields) != 1 || fields[0].Desc.Name() != "png" {
+		t.Errorf("mimeFields(Picture) = %v, want png", fields)
+	}
+}
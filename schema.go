@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemaBuilder builds the JSON Schema of the fields reachable from a tool's
+// input or output message. Messages that are referenced more than once, including
+// recursive ones, are emitted once under $defs and referenced with $ref;
+// every other message is inlined.
+type schemaBuilder struct {
+	cfg  config
+	refs map[protoreflect.FullName]int
+	defs map[string]interface{}
+}
+
+// newSchemaBuilder returns a schemaBuilder for the messages reachable from
+// root. The root counts as referenced once by the tool, so that recursive
+// references to it go through $defs rather than to the tool schema, whose
+// parameters aren't the message's own.
+func newSchemaBuilder(cfg config, root *protogen.Message) *schemaBuilder {
+	b := &schemaBuilder{
+		cfg:  cfg,
+		refs: make(map[protoreflect.FullName]int),
+		defs: make(map[string]interface{}),
+	}
+	b.refs[root.Desc.FullName()]++
+	b.countRefs(root)
+	return b
+}
+
+// streamedSchema returns the schema of one streamed message
+func (b *schemaBuilder) streamedSchema(msg *protogen.Message) map[string]interface{} {
+	if schema := wktSchema(msg); schema != nil {
+		return schema
+	}
+	return b.messageSchema(msg)
+}
+
+// countRefs counts the references to every message reachable from msg,
+// walking each message only once
+func (b *schemaBuilder) countRefs(msg *protogen.Message) {
+	for _, field := range msg.Fields {
+		if field.Desc.IsMap() {
+			field = field.Message.Fields[1]
+		}
+		if field.Message == nil {
+			continue
+		}
+		if wktSchema(field.Message) != nil {
+			continue
+		}
+		name := field.Message.Desc.FullName()
+		b.refs[name]++
+		if b.refs[name] == 1 {
+			b.countRefs(field.Message)
+		}
+	}
+}
+
+// messageSchema returns the schema of a message, either inline or as a $ref
+// to its shared definition
+func (b *schemaBuilder) messageSchema(msg *protogen.Message) map[string]interface{} {
+	name := string(msg.Desc.FullName())
+	if b.refs[msg.Desc.FullName()] < 2 {
+		return b.objectSchema(msg)
+	}
+	if _, ok := b.defs[name]; !ok {
+		// Reserve the definition before building it so recursive
+		// references terminate.
+		b.defs[name] = nil
+		b.defs[name] = b.objectSchema(msg)
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// objectSchema returns the inline object schema of a message
+func (b *schemaBuilder) objectSchema(msg *protogen.Message) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []interface{}
+	for _, field := range msg.Fields {
+		name := field.Desc.JSONName()
+		properties[name] = b.fieldSchema(field)
+		if isRequired(field) {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if desc := commentText(msg.Comments.Leading, msg.Comments.Trailing); desc != "" {
+		schema["description"] = desc
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	var groups []interface{}
+	for _, oneof := range oneofs(msg) {
+		// At most one member may be set: exactly one of them, or none.
+		var members []interface{}
+		for _, field := range oneof.Fields {
+			members = append(members, map[string]interface{}{"required": []interface{}{field.Desc.JSONName()}})
+		}
+		none := map[string]interface{}{"not": map[string]interface{}{"anyOf": members}}
+		groups = append(groups, map[string]interface{}{"oneOf": append(members, none)})
+	}
+	switch len(groups) {
+	case 0:
+	case 1:
+		schema["oneOf"] = groups[0].(map[string]interface{})["oneOf"]
+	default:
+		schema["allOf"] = groups
+	}
+	return schema
+}
+
+// fieldSchema returns the schema of a field, including its description and
+// examples
+func (b *schemaBuilder) fieldSchema(field *protogen.Field) map[string]interface{} {
+	var schema map[string]interface{}
+	switch {
+	case field.Desc.IsMap():
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": b.valueSchema(field.Message.Fields[1]),
+		}
+		if keys := keySchema(field.Message.Fields[0]); keys != nil {
+			schema["propertyNames"] = keys
+		}
+	case isRepeated(field):
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": b.valueSchema(field),
+		}
+	default:
+		schema = b.valueSchema(field)
+	}
+	if rules := fieldRules(field); rules != nil {
+		applyFieldRules(schema, field, rules)
+	}
+	if field.Desc.HasOptionalKeyword() {
+		nullable(schema)
+	}
+	if desc := fieldDescriptionText(field); desc != "" {
+		if _, ok := schema["$ref"]; !ok {
+			schema["description"] = desc
+		}
+	}
+	if examples := fieldOptions(field).GetExamples(); len(examples) > 0 {
+		values := make([]interface{}, len(examples))
+		for i, example := range examples {
+			values[i] = exampleValue(field, example)
+		}
+		schema["examples"] = values
+	}
+	return schema
+}
+
+// valueSchema returns the schema of a single value of a field, ignoring its
+// cardinality
+func (b *schemaBuilder) valueSchema(field *protogen.Field) map[string]interface{} {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return int64Schema(true)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64Schema(false)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.EnumKind:
+		if field.Enum.Desc.FullName() == "google.protobuf.NullValue" {
+			return map[string]interface{}{"type": "null"}
+		}
+		return b.enumSchema(field.Enum)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if schema := wktSchema(field.Message); schema != nil {
+			return schema
+		}
+		return b.messageSchema(field.Message)
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// wktSchema returns the schema of a well-known type following its special
+// JSON mapping, or nil if msg is not a well-known type with one
+func wktSchema(msg *protogen.Message) map[string]interface{} {
+	switch msg.Desc.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return map[string]interface{}{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]{1,9})?s$`}
+	case "google.protobuf.FieldMask":
+		return map[string]interface{}{"type": "string", "description": "Comma-separated field paths"}
+	case "google.protobuf.Struct":
+		return map[string]interface{}{"type": "object"}
+	case "google.protobuf.ListValue":
+		return map[string]interface{}{"type": "array"}
+	case "google.protobuf.Value":
+		return map[string]interface{}{}
+	case "google.protobuf.Empty":
+		return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	case "google.protobuf.Any":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"@type": map[string]interface{}{
+					"type":        "string",
+					"description": "Type URL of the packed message, such as type.googleapis.com/google.protobuf.Duration",
+				},
+			},
+			"required": []interface{}{"@type"},
+		}
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue":
+		return map[string]interface{}{"type": []interface{}{"number", "null"}}
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		schema := int64Schema(msg.Desc.FullName() == "google.protobuf.Int64Value")
+		nullable(schema)
+		return schema
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return map[string]interface{}{"type": []interface{}{"integer", "null"}}
+	case "google.protobuf.BoolValue":
+		return map[string]interface{}{"type": []interface{}{"boolean", "null"}}
+	case "google.protobuf.StringValue":
+		return map[string]interface{}{"type": []interface{}{"string", "null"}}
+	case "google.protobuf.BytesValue":
+		return map[string]interface{}{"type": []interface{}{"string", "null"}, "contentEncoding": "base64"}
+	default:
+		return nil
+	}
+}
+
+// int64Schema returns the schema of a 64-bit integer, which protojson accepts
+// as a number or a decimal string and writes as a string, so that values
+// beyond 2^53 survive JSON
+func int64Schema(signed bool) map[string]interface{} {
+	pattern := "^[0-9]+$"
+	if signed {
+		pattern = "^-?[0-9]+$"
+	}
+	return map[string]interface{}{"type": []interface{}{"integer", "string"}, "pattern": pattern}
+}
+
+// nullable allows null in place of the value described by schema, so that
+// proto3 optional fields can be explicitly left unset
+func nullable(schema map[string]interface{}) {
+	switch typ := schema["type"].(type) {
+	case string:
+		schema["type"] = []interface{}{typ, "null"}
+	case []interface{}:
+		schema["type"] = append(typ, "null")
+	default:
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		schema["enum"] = append(enum, nil)
+	}
+}
+
+// keySchema returns the schema constraining the JSON object keys of a map
+// field with the given key field, or nil for string keys
+func keySchema(key *protogen.Field) map[string]interface{} {
+	switch key.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"enum": []interface{}{"true", "false"}}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return map[string]interface{}{"pattern": "^-?[0-9]+$"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]interface{}{"pattern": "^[0-9]+$"}
+	default:
+		return nil
+	}
+}
+
+// enumSchema returns the schema of an enum, listing its value names and, when
+// enabled, its numbers
+func (b *schemaBuilder) enumSchema(enum *protogen.Enum) map[string]interface{} {
+	var values []interface{}
+	for _, value := range enum.Values {
+		values = append(values, string(value.Desc.Name()))
+	}
+	if !b.cfg.EnumNumbers {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+	for _, value := range enum.Values {
+		values = append(values, float64(value.Desc.Number()))
+	}
+	return map[string]interface{}{"type": []interface{}{"string", "integer"}, "enum": values}
+}
+
+// fieldSchemaOptions returns the extra mcp.PropertyOption arguments describing
+// the structure of a message, map, enum, or repeated field, each preceded by a
+// comma
+func fieldSchemaOptions(g *protogen.GeneratedFile, cfg config, method *protogen.Method, field *protogen.Field) string {
+	schema := newSchemaBuilder(cfg, method.Input).fieldSchema(field)
+	var b strings.Builder
+	switch typ := schema["type"].(type) {
+	case []interface{}:
+		b.WriteString(setKeyword(g, "type", typ))
+	case string:
+		if typ == "object" && field.Message != nil && !field.Desc.IsList() && wktSchema(field.Message) != nil && schema["properties"] == nil {
+			// Free-form object, such as google.protobuf.Struct
+			b.WriteString(mcpCall(g, "PropertyOption", `func(schema map[string]interface{}) { delete(schema, "properties") }`))
+		}
+	case nil:
+		if _, ok := schema["$ref"]; !ok {
+			// Any JSON value, such as google.protobuf.Value
+			b.WriteString(mcpCall(g, "PropertyOption", `func(schema map[string]interface{}) { delete(schema, "type"); delete(schema, "properties") }`))
+		}
+	}
+	for _, keyword := range []string{"format", "contentEncoding"} {
+		if value, ok := schema[keyword]; ok {
+			b.WriteString(setKeyword(g, keyword, value))
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		b.WriteString(mcpCall(g, "Pattern", strconv.Quote(pattern)))
+	}
+	for _, keyword := range []struct{ name, option string }{
+		{"minLength", "MinLength"},
+		{"maxLength", "MaxLength"},
+		{"minimum", "Min"},
+		{"maximum", "Max"},
+		{"minItems", "MinItems"},
+		{"maxItems", "MaxItems"},
+		{"minProperties", "MinProperties"},
+		{"maxProperties", "MaxProperties"},
+	} {
+		if value, ok := schema[keyword.name]; ok {
+			b.WriteString(mcpCall(g, keyword.option, goLiteral(value)))
+		}
+	}
+	for _, keyword := range []string{"exclusiveMinimum", "exclusiveMaximum", "const", "not", "uniqueItems"} {
+		if value, ok := schema[keyword]; ok {
+			b.WriteString(setKeyword(g, keyword, value))
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		if typ, ok := schema["type"].(string); ok && typ == "string" {
+			names := make([]string, len(enum))
+			for i, name := range enum {
+				names[i] = strconv.Quote(name.(string))
+			}
+			b.WriteString(mcpCall(g, "Enum", names...))
+		} else {
+			b.WriteString(setKeyword(g, "enum", enum))
+		}
+	}
+	if ref, ok := schema["$ref"]; ok {
+		b.WriteString(setKeyword(g, "$ref", ref))
+	}
+	if properties, ok := schema["properties"]; ok {
+		b.WriteString(mcpCall(g, "Properties", goLiteral(properties)))
+	}
+	for _, keyword := range []string{"required", "oneOf", "allOf"} {
+		if value, ok := schema[keyword]; ok {
+			b.WriteString(setKeyword(g, keyword, value))
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		b.WriteString(mcpCall(g, "Items", goLiteral(items)))
+	}
+	if values, ok := schema["additionalProperties"]; ok {
+		b.WriteString(mcpCall(g, "AdditionalProperties", goLiteral(values)))
+	}
+	if keys, ok := schema["propertyNames"].(map[string]interface{}); ok {
+		b.WriteString(mcpCall(g, "PropertyNames", goLiteral(keys)))
+	}
+	return b.String()
+}
+
+// mcpCall returns a call of an mcp package function, qualified for the
+// generated file and preceded by a comma
+func mcpCall(g *protogen.GeneratedFile, name string, args ...string) string {
+	return ", " + g.QualifiedGoIdent(mcpPackage.Ident(name)) + "(" + strings.Join(args, ", ") + ")"
+}
+
+// setKeyword returns an mcp.PropertyOption argument setting a schema keyword
+// to a value, preceded by a comma
+func setKeyword(g *protogen.GeneratedFile, keyword string, value interface{}) string {
+	return mcpCall(g, "PropertyOption", "func(schema map[string]interface{}) { schema["+strconv.Quote(keyword)+"] = "+goLiteral(value)+" }")
+}
+
+// inputDefs returns a mcp.ToolOption setting the $defs of a method's input
+// schema, or an empty string when the input has no shared messages
+func inputDefs(g *protogen.GeneratedFile, cfg config, method *protogen.Method) string {
+	var b *schemaBuilder
+	if isClientStreaming(method) {
+		b = newSchemaBuilder(cfg, method.Input)
+		b.streamedSchema(method.Input)
+	} else {
+		b = newSchemaBuilder(cfg, method.Input)
+		for _, field := range method.Input.Fields {
+			b.fieldSchema(field)
+		}
+	}
+	if len(b.defs) == 0 {
+		return ""
+	}
+	return "func(t *" + g.QualifiedGoIdent(mcpPackage.Ident("Tool")) + ") { t.InputSchema.Defs = " + goLiteral(b.defs) + " }"
+}
+
+// itemsSchemaOptions returns the mcp.PropertyOption arguments describing the
+// items parameter of a client-streaming method, each preceded by a comma
+func itemsSchemaOptions(g *protogen.GeneratedFile, cfg config, method *protogen.Method) string {
+	items := newSchemaBuilder(cfg, method.Input).streamedSchema(method.Input)
+	return mcpCall(g, "Items", goLiteral(items))
+}
+
+// outputSchema returns the JSON Schema of a method's output message as a Go
+// string literal
+func outputSchema(cfg config, method *protogen.Method) string {
+	var b *schemaBuilder
+	var schema map[string]interface{}
+	switch {
+	case isServerStreaming(method):
+		// Streamed messages are returned together as {"messages": [...]}.
+		b = newSchemaBuilder(cfg, method.Output)
+		schema = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"messages": map[string]interface{}{
+					"type":  "array",
+					"items": b.streamedSchema(method.Output),
+				},
+			},
+			"required": []interface{}{"messages"},
+		}
+	case isBidiStreaming(method):
+		// The _receive and _close session tools return the messages sent since
+		// the previous call and the state of the session.
+		b = newSchemaBuilder(cfg, method.Output)
+		schema = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"messages": map[string]interface{}{
+					"type":  "array",
+					"items": b.streamedSchema(method.Output),
+				},
+				"done": map[string]interface{}{"type": "boolean"},
+				"error": map[string]interface{}{
+					"description": "gRPC status of the error the method returned, as google.rpc.Status",
+					"type":        "object",
+					"properties": map[string]interface{}{
+						"code":    map[string]interface{}{"type": "integer"},
+						"message": map[string]interface{}{"type": "string"},
+						"details": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+					},
+				},
+			},
+			"required": []interface{}{"messages", "done"},
+		}
+	case wrapsOutput(method.Output):
+		b = newSchemaBuilder(cfg, method.Output)
+		schema = map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"value": wktSchema(method.Output)},
+			"required":   []interface{}{"value"},
+		}
+	case isWellKnown(method.Output):
+		b = newSchemaBuilder(cfg, method.Output)
+		schema = wktSchema(method.Output)
+	default:
+		b = newSchemaBuilder(cfg, method.Output)
+		schema = b.objectSchema(method.Output)
+		// Fields with a MIME type are returned as their own content instead
+		// of in the structured content.
+		for _, field := range mimeFields(method.Output) {
+			delete(schema["properties"].(map[string]interface{}), field.Desc.JSONName())
+			if required, ok := schema["required"].([]interface{}); ok {
+				kept := required[:0]
+				for _, name := range required {
+					if name != field.Desc.JSONName() {
+						kept = append(kept, name)
+					}
+				}
+				schema["required"] = kept
+			}
+		}
+	}
+	if len(b.defs) > 0 {
+		schema["$defs"] = b.defs
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(schema); err != nil {
+		panic(err) // the schema only holds JSON values
+	}
+	text := strings.TrimSpace(buf.String())
+	if strings.Contains(text, "`") {
+		return strconv.Quote(text)
+	}
+	return "`" + text + "`"
+}
+
+// goLiteral renders a JSON-like value as a Go expression. Map keys are sorted
+// so the generated code is stable.
+func goLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("map[string]interface{}{\n")
+		for _, k := range keys {
+			b.WriteString(strconv.Quote(k) + ": " + goLiteral(v[k]) + ",\n")
+		}
+		b.WriteString("}")
+		return b.String()
+	case []interface{}:
+		values := make([]string, len(v))
+		multiline := false
+		for i, value := range v {
+			values[i] = goLiteral(value)
+			multiline = multiline || strings.Contains(values[i], "\n")
+		}
+		if multiline {
+			return "[]interface{}{\n" + strings.Join(values, ",\n") + ",\n}"
+		}
+		return "[]interface{}{" + strings.Join(values, ", ") + "}"
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return "nil"
+	}
+}
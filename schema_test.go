@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestRecursiveSchema(t *testing.T) {
+	gen, cfg, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := findMessage(t, gen, "schematest.Node")
+	b := newSchemaBuilder(cfg, node)
+	schema := b.objectSchema(node)
+	properties := schema["properties"].(map[string]interface{})
+
+	ref := map[string]interface{}{"$ref": "#/$defs/schematest.Node"}
+	if !reflect.DeepEqual(properties["parent"], ref) {
+		t.Errorf("parent = %v, want %v", properties["parent"], ref)
+	}
+	if _, ok := properties["only"].(map[string]interface{})["properties"]; !ok {
+		t.Errorf("only = %v, want the inline schema of a message referenced once", properties["only"])
+	}
+	for _, name := range []string{"schematest.Node", "schematest.Leaf"} {
+		if _, ok := b.defs[name]; !ok {
+			t.Errorf("no definition of %s", name)
+		}
+	}
+	if _, ok := b.defs["schematest.Only"]; ok {
+		t.Error("schematest.Only is defined although it is referenced once")
+	}
+	out, err := json.Marshal(map[string]interface{}{"schema": schema, "defs": b.defs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), `"$ref":"#"`) {
+		t.Errorf("schema refers to the tool schema root: %s", out)
+	}
+}
+
+func TestFieldSchema(t *testing.T) {
+	gen, cfg, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := findMessage(t, gen, "schematest.Scalars")
+	for _, tt := range []struct {
+		field string
+		want  map[string]interface{}
+	}{
+		{"signed", map[string]interface{}{"type": []interface{}{"integer", "string"}, "pattern": "^-?[0-9]+$"}},
+		{"unsigned", map[string]interface{}{"type": []interface{}{"integer", "string"}, "pattern": "^[0-9]+$"}},
+		{"wrapped", map[string]interface{}{"type": []interface{}{"integer", "string", "null"}, "pattern": "^-?[0-9]+$"}},
+		{"by_int", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+			"propertyNames":        map[string]interface{}{"pattern": "^-?[0-9]+$"},
+		}},
+		{"by_uint", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+			"propertyNames":        map[string]interface{}{"pattern": "^[0-9]+$"},
+		}},
+		{"by_bool", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+			"propertyNames":        map[string]interface{}{"enum": []interface{}{"true", "false"}},
+		}},
+		{"by_string", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		}},
+		{"maybe", map[string]interface{}{
+			"type": []interface{}{"string", "null"},
+			"enum": []interface{}{"COLOR_UNSPECIFIED", "COLOR_RED", "COLOR_GREEN", nil},
+		}},
+		{"flag", map[string]interface{}{"type": "boolean", "examples": []interface{}{true, "maybe"}}},
+		{"count", map[string]interface{}{"type": "integer", "examples": []interface{}{float64(3)}}},
+		{"big", map[string]interface{}{
+			"type":     []interface{}{"integer", "string"},
+			"pattern":  "^-?[0-9]+$",
+			"examples": []interface{}{"9007199254740993"},
+		}},
+	} {
+		got := newSchemaBuilder(cfg, msg).fieldSchema(findField(t, msg, protoreflect.Name(tt.field)))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("schema of %s = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestEnumSchema(t *testing.T) {
+	gen, cfg, err := newPlugin(t, "enum_numbers=true", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := findMessage(t, gen, "schematest.Leaf")
+	got := newSchemaBuilder(cfg, msg).fieldSchema(findField(t, msg, "color"))
+	want := map[string]interface{}{
+		"type": []interface{}{"string", "integer"},
+		"enum": []interface{}{"COLOR_UNSPECIFIED", "COLOR_RED", "COLOR_GREEN", float64(0), float64(1), float64(2)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schema of color = %v, want %v", got, want)
+	}
+}
+
+func TestOutputSchema(t *testing.T) {
+	gen, cfg, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		method string
+		want   string
+	}{
+		{"GetHTTPStatus", `{"properties":{"value":{"format":"date-time","type":"string"}},"required":["value"],"type":"object"}`},
+		{"Describe", `{"type":"object"}`},
+		{"Draw", `{"properties":{"caption":{"type":"string"}},"required":["caption"],"type":"object"}`},
+	} {
+		literal := outputSchema(cfg, findMethod(t, gen, protoreflect.FullName("schematest.Things."+tt.method)))
+		var got, want interface{}
+		if err := json.Unmarshal([]byte(strings.Trim(literal, "`")), &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("output schema of %s = %s, want %s", tt.method, literal, tt.want)
+		}
+	}
+
+	// Streamed messages are returned as a list, with the recursive message
+	// defined once.
+	literal := outputSchema(cfg, findMethod(t, gen, "schematest.Things.Walk"))
+	var got struct {
+		Properties struct {
+			Messages struct {
+				Items map[string]interface{} `json:"items"`
+			} `json:"messages"`
+		} `json:"properties"`
+		Required []string               `json:"required"`
+		Defs     map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal([]byte(strings.Trim(literal, "`")), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Properties.Messages.Items["$ref"] != "#/$defs/schematest.Node" || got.Defs["schematest.Node"] == nil ||
+		!reflect.DeepEqual(got.Required, []string{"messages"}) {
+		t.Errorf("output schema of Walk = %s", literal)
+	}
+}
+
+func TestGoLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		value interface{}
+		want  string
+	}{
+		{"a\"b", `"a\"b"`},
+		{true, "true"},
+		{float64(1.5), "1.5"},
+		{nil, "nil"},
+		{[]interface{}{"a", float64(1)}, `[]interface{}{"a", 1}`},
+		{map[string]interface{}{"b": float64(2), "a": "x"}, "map[string]interface{}{\n\"a\": \"x\",\n\"b\": 2,\n}"},
+		{[]interface{}{map[string]interface{}{}}, "[]interface{}{\nmap[string]interface{}{\n},\n}"},
+	} {
+		if got := goLiteral(tt.value); got != tt.want {
+			t.Errorf("goLiteral(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
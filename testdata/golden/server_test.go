@@ -0,0 +1,373 @@
+package pb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// params are the plugin parameters the code was generated with
+var params = os.Getenv("GOLDEN_PARAMS")
+
+type tree struct{}
+
+func (tree) Get(ctx context.Context, req *Node) (*Node, error) {
+	return req, nil
+}
+
+func (tree) Walk(ctx context.Context, req *Node, send func(*Node) error) error {
+	for _, child := range req.Children {
+		if err := send(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tree) Plant(ctx context.Context, stream TreeMcpServer_PlantStream) (*Node, error) {
+	res := &Node{}
+	for {
+		node, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Id += node.Id
+	}
+}
+
+func (tree) Graft(ctx context.Context, stream TreeMcpServer_GraftStream) error {
+	for {
+		node, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(node); err != nil {
+			return err
+		}
+	}
+}
+
+func (tree) Now(ctx context.Context, req *emptypb.Empty) (*timestamppb.Timestamp, error) {
+	return timestamppb.New(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), nil
+}
+
+func (tree) Sum(ctx context.Context, stream TreeMcpServer_SumStream) (*wrapperspb.Int64Value, error) {
+	res := &wrapperspb.Int64Value{}
+	for {
+		value, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Value += value.Value
+	}
+}
+
+func (tree) Render(ctx context.Context, req *Node) (*Picture, error) {
+	return &Picture{Caption: req.DisplayName, Png: []byte("\x89PNG")}, nil
+}
+
+type otp struct{}
+
+func (otp) Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	if req.UserId != 1 {
+		return nil, status.Error(codes.PermissionDenied, "bad code")
+	}
+	return &VerifyResponse{Ok: true}, nil
+}
+
+// result is a tools/call result
+type result struct {
+	Content []struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		MimeType string `json:"mimeType"`
+	} `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent"`
+	IsError           bool            `json:"isError"`
+}
+
+// text returns the text contents of a result
+func (r result) text() string {
+	var texts []string
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			texts = append(texts, c.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// toolName returns the name of a tool under the tool_naming parameter
+func toolName(name string) string {
+	if strings.Contains(params, "tool_naming=snake") {
+		return strings.ToLower(name[:1]) + name[1:]
+	}
+	return name
+}
+
+// paramName returns the name of a top-level parameter under the field_naming
+// parameter, for the single-word fields the tests check errors of
+func paramName(name string) string {
+	if strings.Contains(params, "field_naming=go") {
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	return name
+}
+
+func newServer() *server.MCPServer {
+	return NewMcpServer("golden", "1", McpServices{Tree: tree{}, Otp: otp{}})
+}
+
+// handle sends a JSON-RPC request to the server and returns its result
+func handle(t *testing.T, s *server.MCPServer, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	req, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(s.HandleMessage(context.Background(), req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("%s: %s", method, resp.Error.Message)
+	}
+	return resp.Result
+}
+
+// call calls a tool and returns its result
+func call(t *testing.T, s *server.MCPServer, name string, args map[string]interface{}) result {
+	t.Helper()
+	var res result
+	out := handle(t, s, "tools/call", map[string]interface{}{"name": toolName(name), "arguments": args})
+	if err := json.Unmarshal(out, &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestToolErrors(t *testing.T) {
+	s := newServer()
+	for _, tt := range []struct {
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"Get", map[string]interface{}{"id": 1e16}, "invalid value for id: integers beyond 2^53 must be passed as decimal strings"},
+		{"Get", map[string]interface{}{"parent": map[string]interface{}{"id": 1e16}}, "invalid value for parent.id:"},
+		{"Get", map[string]interface{}{"counters": map[string]interface{}{"a": 1e16}}, `invalid value for counters["a"]:`},
+		{"Get", map[string]interface{}{"tags": []interface{}{1, 1e16}}, "invalid value for tags[1]:"},
+		{"Get", map[string]interface{}{"limit": 1e16}, "invalid value for limit:"},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1e16}}}, "invalid items[0]: invalid value for id:"},
+		{"Sum", map[string]interface{}{"items": []interface{}{1e16}}, "invalid items[0]: integers beyond 2^53 must be passed as decimal strings"},
+		{"Get", map[string]interface{}{"shade": "SHADE_LIGHT"}, `invalid value "SHADE_LIGHT" for shade`},
+		{"Get", map[string]interface{}{"shade": 5}, "invalid value 5 for shade"},
+		{"Get", map[string]interface{}{"parent": map[string]interface{}{"shade": 5}}, "invalid value 5 for parent.shade"},
+		{"Get", map[string]interface{}{"named": map[string]interface{}{"a": map[string]interface{}{"shade": "SHADE_LIGHT"}}}, `invalid value "SHADE_LIGHT" for named["a"].shade`},
+		{"Get", map[string]interface{}{"label": map[string]interface{}{}}, "missing required field label.text"},
+		{"Get", map[string]interface{}{"parent": map[string]interface{}{"label": map[string]interface{}{"text": nil}}}, "missing required field parent.label.text"},
+		{"Get", map[string]interface{}{"named": map[string]interface{}{"a": map[string]interface{}{"label": map[string]interface{}{}}}}, `missing required field named["a"].label.text`},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"children": []interface{}{map[string]interface{}{"label": map[string]interface{}{}}}}}}, "invalid items[0]: missing required field children[0].label.text"},
+		{"Get", map[string]interface{}{"display_name": "a", "displayName": "b"}, "invalid arguments: displayName is given under more than one name"},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"display_name": "a", "displayName": "b"}}}, "invalid items[0]: field displayName is given under more than one name"},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{}, map[string]interface{}{"shade": "SHADE_LIGHT"}}}, `invalid items[1]: invalid value "SHADE_LIGHT" for shade`},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"children": []interface{}{map[string]interface{}{"shade": 5}}}}}, "invalid items[0]: invalid value 5 for children[0].shade"},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"seed": "!"}}}, "invalid items[0]: invalid value for " + paramName("seed") + ": must be base64"},
+		{"Verify", map[string]interface{}{"code": "!"}, "invalid value for " + paramName("code") + ": must be base64"},
+		{"Verify", map[string]interface{}{"userId": 2}, "PermissionDenied: bad code"},
+	} {
+		res := call(t, s, tt.tool, tt.args)
+		if !res.IsError || !strings.Contains(res.text(), tt.want) {
+			t.Errorf("%s(%v) = %q, want an error containing %q", tt.tool, tt.args, res.text(), tt.want)
+		}
+	}
+}
+
+func TestToolResults(t *testing.T) {
+	s := newServer()
+	for _, tt := range []struct {
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"Get", map[string]interface{}{"id": "9007199254740993"}, "9007199254740993"},
+		{"Get", map[string]interface{}{"shade": "SHADE_DARK"}, "SHADE_DARK"},
+		{"Get", map[string]interface{}{"parent": map[string]interface{}{"label": map[string]interface{}{"text": "oak"}}}, "oak"},
+		{"Walk", map[string]interface{}{"children": []interface{}{map[string]interface{}{"displayName": "oak"}}}, "oak"},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 2}, map[string]interface{}{"id": "3"}}}, "5"},
+		{"Sum", map[string]interface{}{"items": []interface{}{2, "3"}}, "5"},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": "9007199254740993"}, map[string]interface{}{"id": 1}}}, "9007199254740994"},
+		{"Sum", map[string]interface{}{"items": []interface{}{"9007199254740993", 1}}, "9007199254740994"},
+		{"Render", map[string]interface{}{"displayName": "oak"}, "oak"},
+	} {
+		res := call(t, s, tt.tool, tt.args)
+		if res.IsError || !strings.Contains(res.text()+string(res.StructuredContent), tt.want) {
+			t.Errorf("%s(%v) = %q, want a result containing %q", tt.tool, tt.args, res.text(), tt.want)
+		}
+	}
+}
+
+func TestWellKnownResult(t *testing.T) {
+	// Every output returns the JSON form of a well-known type, text output
+	// included
+	want := "2020-01-02T03:04:05Z"
+	res := call(t, newServer(), "Now", map[string]interface{}{})
+	if res.IsError || !strings.Contains(res.text()+string(res.StructuredContent), want) {
+		t.Errorf("Now = %q, want a result containing %q", res.text(), want)
+	}
+}
+
+func TestEnumNumbers(t *testing.T) {
+	res := call(t, newServer(), "Get", map[string]interface{}{"shade": 1})
+	if strings.Contains(params, "enum_numbers=true") == res.IsError {
+		t.Errorf("Get with shade 1 = %q", res.text())
+	}
+}
+
+func TestStructuredContent(t *testing.T) {
+	if !strings.Contains(params, "output=structured") {
+		t.Skip("output is not structured")
+	}
+	res := call(t, newServer(), "Now", map[string]interface{}{})
+	var out map[string]interface{}
+	if err := json.Unmarshal(res.StructuredContent, &out); err != nil || out["value"] != "2020-01-02T03:04:05Z" {
+		t.Errorf("Now structuredContent = %s, want an object with the timestamp as value", res.StructuredContent)
+	}
+}
+
+func TestViolations(t *testing.T) {
+	validate := strings.Contains(params, "validate=true")
+	long := strings.Repeat("a", 21)
+	s := newServer()
+	for _, tt := range []struct {
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"Get", map[string]interface{}{"displayName": long}, `"field":"display_name"`},
+		{"Plant", map[string]interface{}{"items": []interface{}{map[string]interface{}{}, map[string]interface{}{"displayName": long}}}, `"item":1`},
+	} {
+		res := call(t, s, tt.tool, tt.args)
+		if res.IsError != validate {
+			t.Errorf("%s(%v) = %q, want an error only with validate=true", tt.tool, tt.args, res.text())
+			continue
+		}
+		if !validate {
+			continue
+		}
+		var out struct {
+			Violations []map[string]interface{} `json:"violations"`
+		}
+		if err := json.Unmarshal(res.StructuredContent, &out); err != nil || len(out.Violations) != 1 ||
+			out.Violations[0]["rule"] != "string.max_len" || !strings.Contains(string(res.StructuredContent), tt.want) {
+			t.Errorf("%s(%v) structuredContent = %s, want a string.max_len violation with %s", tt.tool, tt.args, res.StructuredContent, tt.want)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	res := call(t, newServer(), "Verify", map[string]interface{}{"userId": 2})
+	var out map[string]interface{}
+	if err := json.Unmarshal(res.StructuredContent, &out); err != nil || out["code"] != float64(codes.PermissionDenied) || out["message"] != "bad code" {
+		t.Errorf("Verify structuredContent = %s, want the status", res.StructuredContent)
+	}
+}
+
+func TestImageContent(t *testing.T) {
+	res := call(t, newServer(), "Render", map[string]interface{}{})
+	for _, c := range res.Content {
+		if c.Type == "image" && c.MimeType == "image/png" {
+			return
+		}
+	}
+	t.Errorf("Render returned no image/png content: %+v", res.Content)
+}
+
+func TestToolSchemas(t *testing.T) {
+	out := handle(t, newServer(), "tools/list", map[string]interface{}{})
+	if strings.Contains(string(out), `"$ref":"#"`) {
+		t.Errorf("tools/list refers to a schema root: %s", out)
+	}
+	var list struct {
+		Tools []struct {
+			Name        string `json:"name"`
+			InputSchema struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+				Defs       map[string]struct {
+					Properties map[string]json.RawMessage `json:"properties"`
+				} `json:"$defs"`
+			} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range list.Tools {
+		if tool.Name != toolName("Graft_send") {
+			continue
+		}
+		if _, ok := tool.InputSchema.Properties["session"]; !ok {
+			t.Errorf("%s has no session parameter", tool.Name)
+		}
+		if _, ok := tool.InputSchema.Defs["golden.Node"].Properties["session"]; ok {
+			t.Errorf("%s has a session property in the golden.Node definition", tool.Name)
+		}
+		return
+	}
+	t.Errorf("no %s tool", toolName("Graft_send"))
+}
+
+func TestSession(t *testing.T) {
+	s := newServer()
+	res := call(t, s, "Graft_open", map[string]interface{}{})
+	var open struct {
+		Session string `json:"session"`
+	}
+	if err := json.Unmarshal([]byte(res.text()), &open); err != nil || open.Session == "" {
+		t.Fatalf("Graft_open = %q", res.text())
+	}
+	for i := 1; i <= 2; i++ {
+		res = call(t, s, "Graft_send", map[string]interface{}{"session": open.Session, "displayName": fmt.Sprint("node", i)})
+		if res.IsError {
+			t.Fatalf("Graft_send = %q", res.text())
+		}
+	}
+	res = call(t, s, "Graft_close", map[string]interface{}{"session": open.Session})
+	if res.IsError || !strings.Contains(res.text(), "node1") || !strings.Contains(res.text(), "node2") {
+		t.Errorf("Graft_close = %q, want both nodes", res.text())
+	}
+	res = call(t, s, "Graft_receive", map[string]interface{}{"session": open.Session})
+	if !res.IsError {
+		t.Errorf("Graft_receive after Graft_close = %q, want an unknown session error", res.text())
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// stringFormats maps the protovalidate well-known string rules to the JSON
+// Schema formats they correspond to
+var stringFormats = map[protoreflect.Name]string{
+	"email":    "email",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"uri":      "uri",
+	"uri_ref":  "uri-reference",
+	"uuid":     "uuid",
+}
+
+// fieldRules returns the protovalidate (buf.validate.field) rules set on a
+// field, or nil when it has none or they are always ignored
+func fieldRules(field *protogen.Field) protoreflect.Message {
+	value, ok := customOption(field.Desc, "buf.validate.field")
+	if !ok {
+		return nil
+	}
+	rules := value.Message()
+	if ignore, ok := ruleValue(rules, "ignore"); ok && ignore.Enum() == 3 { // buf.validate.Ignore.IGNORE_ALWAYS
+		return nil
+	}
+	return rules
+}
+
+// ruleValue returns the value of a rule, with ok false when the rule is not
+// set or not known to the rules message
+func ruleValue(rules protoreflect.Message, name protoreflect.Name) (value protoreflect.Value, ok bool) {
+	fd := rules.Descriptor().Fields().ByName(name)
+	if fd == nil || !rules.Has(fd) {
+		return protoreflect.Value{}, false
+	}
+	return rules.Get(fd), true
+}
+
+// applyFieldRules adds the JSON Schema keywords equivalent to the protovalidate
+// rules of a field to its schema. Rules without a JSON Schema counterpart are
+// left to the runtime validation.
+func applyFieldRules(schema map[string]interface{}, field *protogen.Field, rules protoreflect.Message) {
+	if repeated, ok := ruleValue(rules, "repeated"); ok {
+		r := repeated.Message()
+		setCount(schema, "minItems", r, "min_items")
+		setCount(schema, "maxItems", r, "max_items")
+		if unique, ok := ruleValue(r, "unique"); ok && unique.Bool() {
+			schema["uniqueItems"] = true
+		}
+		if items, ok := ruleValue(r, "items"); ok {
+			if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+				applyValueRules(itemSchema, field.Desc.Kind(), field.Enum, items.Message())
+			}
+		}
+		return
+	}
+	if m, ok := ruleValue(rules, "map"); ok {
+		r := m.Message()
+		setCount(schema, "minProperties", r, "min_pairs")
+		setCount(schema, "maxProperties", r, "max_pairs")
+		key, value := field.Message.Fields[0], field.Message.Fields[1]
+		if keys, ok := ruleValue(r, "keys"); ok && key.Desc.Kind() == protoreflect.StringKind {
+			keySchema, _ := schema["propertyNames"].(map[string]interface{})
+			if keySchema == nil {
+				keySchema = map[string]interface{}{}
+			}
+			applyValueRules(keySchema, key.Desc.Kind(), nil, keys.Message())
+			if len(keySchema) > 0 {
+				schema["propertyNames"] = keySchema
+			}
+		}
+		if values, ok := ruleValue(r, "values"); ok {
+			if valueSchema, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				applyValueRules(valueSchema, value.Desc.Kind(), value.Enum, values.Message())
+			}
+		}
+		return
+	}
+	applyValueRules(schema, field.Desc.Kind(), field.Enum, rules)
+}
+
+// applyValueRules adds the keywords for the type-specific rules of a single
+// value of the given kind
+func applyValueRules(schema map[string]interface{}, kind protoreflect.Kind, enum *protogen.Enum, rules protoreflect.Message) {
+	switch kind {
+	case protoreflect.StringKind:
+		r, ok := ruleValue(rules, "string")
+		if !ok {
+			return
+		}
+		applyStringRules(schema, r.Message())
+	case protoreflect.EnumKind:
+		r, ok := ruleValue(rules, "enum")
+		if !ok {
+			return
+		}
+		applyEnumRules(schema, enum, r.Message())
+	case protoreflect.BoolKind:
+		if r, ok := ruleValue(rules, "bool"); ok {
+			if c, ok := ruleValue(r.Message(), "const"); ok {
+				schema["const"] = c.Bool()
+			}
+		}
+	default:
+		// Numeric rules are named after the scalar type, such as int32 or
+		// sfixed64, which matches the kind name.
+		r, ok := ruleValue(rules, protoreflect.Name(kind.String()))
+		if !ok || r.Message().Descriptor().Fields().ByName("gte") == nil {
+			return
+		}
+		applyNumberRules(schema, r.Message())
+	}
+}
+
+// applyStringRules adds the keywords for protovalidate string rules
+func applyStringRules(schema map[string]interface{}, r protoreflect.Message) {
+	if c, ok := ruleValue(r, "const"); ok {
+		schema["const"] = c.String()
+	}
+	if n, ok := ruleValue(r, "len"); ok {
+		schema["minLength"] = float64(n.Uint())
+		schema["maxLength"] = float64(n.Uint())
+	}
+	setCount(schema, "minLength", r, "min_len")
+	setCount(schema, "maxLength", r, "max_len")
+	if pattern, ok := ruleValue(r, "pattern"); ok {
+		schema["pattern"] = pattern.String()
+	}
+	if in := listValues(r, "in"); len(in) > 0 {
+		schema["enum"] = in
+	}
+	if notIn := listValues(r, "not_in"); len(notIn) > 0 {
+		schema["not"] = map[string]interface{}{"enum": notIn}
+	}
+	for name, format := range stringFormats {
+		if v, ok := ruleValue(r, name); ok && v.Bool() {
+			schema["format"] = format
+		}
+	}
+}
+
+// applyNumberRules adds the keywords for protovalidate numeric rules, which
+// share their field names across the numeric types
+func applyNumberRules(schema map[string]interface{}, r protoreflect.Message) {
+	if c, ok := ruleValue(r, "const"); ok {
+		if forms := numberForms(c); len(forms) == 1 {
+			schema["const"] = forms[0]
+		} else {
+			schema["enum"] = forms
+		}
+	}
+	lower, upper := map[string]interface{}{}, map[string]interface{}{}
+	for rule, keyword := range map[protoreflect.Name]string{"gt": "exclusiveMinimum", "gte": "minimum"} {
+		if v, ok := ruleValue(r, rule); ok {
+			lower[keyword] = number(v)
+		}
+	}
+	for rule, keyword := range map[protoreflect.Name]string{"lt": "exclusiveMaximum", "lte": "maximum"} {
+		if v, ok := ruleValue(r, rule); ok {
+			upper[keyword] = number(v)
+		}
+	}
+	// A lower bound above the upper bound means the value must lie outside
+	// the range, which JSON Schema can't express without anyOf.
+	for _, l := range lower {
+		for _, u := range upper {
+			if l.(float64) > u.(float64) {
+				return
+			}
+		}
+	}
+	for keyword, v := range lower {
+		schema[keyword] = v
+	}
+	for keyword, v := range upper {
+		schema[keyword] = v
+	}
+	if in := listValues(r, "in"); len(in) > 0 {
+		schema["enum"] = in
+	}
+	if notIn := listValues(r, "not_in"); len(notIn) > 0 {
+		schema["not"] = map[string]interface{}{"enum": notIn}
+	}
+}
+
+// applyEnumRules narrows the values listed in an enum schema to those allowed
+// by protovalidate enum rules
+func applyEnumRules(schema map[string]interface{}, enum *protogen.Enum, r protoreflect.Message) {
+	values, ok := schema["enum"].([]interface{})
+	if !ok || enum == nil {
+		return
+	}
+	allowed := func(number protoreflect.EnumNumber) bool {
+		if c, ok := ruleValue(r, "const"); ok && protoreflect.EnumNumber(c.Int()) != number {
+			return false
+		}
+		if in := listValues(r, "in"); len(in) > 0 && !containsNumber(in, number) {
+			return false
+		}
+		return !containsNumber(listValues(r, "not_in"), number)
+	}
+	var kept []interface{}
+	for _, value := range values {
+		switch value := value.(type) {
+		case string:
+			if v := enum.Desc.Values().ByName(protoreflect.Name(value)); v != nil && !allowed(v.Number()) {
+				continue
+			}
+		case float64:
+			if !allowed(protoreflect.EnumNumber(value)) {
+				continue
+			}
+		}
+		kept = append(kept, value)
+	}
+	schema["enum"] = kept
+}
+
+// containsNumber reports whether a list of rule values holds an enum number
+func containsNumber(values []interface{}, number protoreflect.EnumNumber) bool {
+	for _, v := range values {
+		if v == float64(number) {
+			return true
+		}
+	}
+	return false
+}
+
+// setCount sets a schema keyword from an unsigned count rule when it is set
+func setCount(schema map[string]interface{}, keyword string, r protoreflect.Message, rule protoreflect.Name) {
+	if v, ok := ruleValue(r, rule); ok {
+		schema[keyword] = float64(v.Uint())
+	}
+}
+
+// listValues returns the elements of a repeated rule as JSON values
+func listValues(r protoreflect.Message, rule protoreflect.Name) []interface{} {
+	v, ok := ruleValue(r, rule)
+	if !ok {
+		return nil
+	}
+	list := v.List()
+	values := make([]interface{}, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if s, ok := list.Get(i).Interface().(string); ok {
+			values = append(values, s)
+		} else {
+			values = append(values, numberForms(list.Get(i))...)
+		}
+	}
+	return values
+}
+
+// numberForms returns the JSON values a numeric rule value may take. 64-bit
+// integers are also accepted as decimal strings, and only as strings when the
+// value is beyond 2^53.
+func numberForms(v protoreflect.Value) []interface{} {
+	var s string
+	switch n := v.Interface().(type) {
+	case int64:
+		s = strconv.FormatInt(n, 10)
+	case uint64:
+		s = strconv.FormatUint(n, 10)
+	default:
+		return []interface{}{number(v)}
+	}
+	if f := number(v).(float64); math.Abs(f) >= 1<<53 {
+		return []interface{}{s}
+	}
+	return []interface{}{number(v), s}
+}
+
+// number returns a numeric rule value as a JSON number
+func number(v protoreflect.Value) interface{} {
+	switch n := v.Interface().(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	case protoreflect.EnumNumber:
+		return float64(n)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestFieldRules(t *testing.T) {
+	gen, cfg, err := newPlugin(t, "", "schema.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := findMessage(t, gen, "schematest.Rules")
+	int64Type := []interface{}{"integer", "string"}
+	for _, tt := range []struct {
+		field string
+		want  map[string]interface{}
+	}{
+		{"email", map[string]interface{}{"type": "string", "format": "email"}},
+		{"sized", map[string]interface{}{"type": "string", "minLength": float64(2), "maxLength": float64(5)}},
+		{"code", map[string]interface{}{"type": "string", "minLength": float64(4), "maxLength": float64(4), "pattern": "^[A-Z]+$"}},
+		{"id", map[string]interface{}{"type": "string", "format": "uuid"}},
+		{"choice", map[string]interface{}{
+			"type": "string",
+			"enum": []interface{}{"a", "b"},
+			"not":  map[string]interface{}{"enum": []interface{}{"c"}},
+		}},
+		{"percent", map[string]interface{}{"type": "integer", "minimum": float64(0), "maximum": float64(100)}},
+		{"ratio", map[string]interface{}{"type": "number", "exclusiveMinimum": float64(0), "exclusiveMaximum": float64(1)}},
+		// A range with its bounds swapped excludes the values between them.
+		{"outside", map[string]interface{}{"type": "integer"}},
+		{"known", map[string]interface{}{
+			"type":    int64Type,
+			"pattern": "^-?[0-9]+$",
+			"enum":    []interface{}{float64(1), "1", "9007199254740993"},
+		}},
+		{"banned", map[string]interface{}{
+			"type":    int64Type,
+			"pattern": "^[0-9]+$",
+			"not":     map[string]interface{}{"enum": []interface{}{float64(7), "7"}},
+		}},
+		{"fixed", map[string]interface{}{
+			"type":    int64Type,
+			"pattern": "^-?[0-9]+$",
+			"enum":    []interface{}{float64(42), "42"},
+		}},
+		{"tags", map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string", "minLength": float64(1)},
+			"minItems":    float64(1),
+			"maxItems":    float64(3),
+			"uniqueItems": true,
+		}},
+		{"scores", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "integer", "minimum": float64(0)},
+			"propertyNames":        map[string]interface{}{"pattern": "^[a-z]+$"},
+			"minProperties":        float64(1),
+		}},
+		{"color", map[string]interface{}{"type": "string", "enum": []interface{}{"COLOR_RED", "COLOR_GREEN"}}},
+		{"agreed", map[string]interface{}{"type": "boolean", "const": true}},
+		{"ignored", map[string]interface{}{"type": "string"}},
+	} {
+		got := newSchemaBuilder(cfg, msg).fieldSchema(findField(t, msg, protoreflect.Name(tt.field)))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("schema of %s = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestNumberForms(t *testing.T) {
+	for _, tt := range []struct {
+		value protoreflect.Value
+		want  []interface{}
+	}{
+		{protoreflect.ValueOfInt32(-3), []interface{}{float64(-3)}},
+		{protoreflect.ValueOfFloat64(0.5), []interface{}{0.5}},
+		{protoreflect.ValueOfInt64(-3), []interface{}{float64(-3), "-3"}},
+		{protoreflect.ValueOfInt64(-1 << 53), []interface{}{"-9007199254740992"}},
+		{protoreflect.ValueOfUint64(1<<53 - 1), []interface{}{float64(1<<53 - 1), "9007199254740991"}},
+		{protoreflect.ValueOfUint64(1<<64 - 1), []interface{}{"18446744073709551615"}},
+	} {
+		if got := numberForms(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("numberForms(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}